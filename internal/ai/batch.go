@@ -0,0 +1,133 @@
+package ai
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultBatchTokens is the estimated number of input tokens per batch when
+// MT_BATCH_TOKENS is not set. Responses are about as long, so this keeps both
+// well inside the context and output limits of current models.
+const DefaultBatchTokens = 2000
+
+// DefaultConcurrency is the number of batches translated at the same time when
+// MT_CONCURRENCY is not set
+const DefaultConcurrency = 3
+
+// DefaultRepairAttempts is how often translations that fail validation are
+// sent back to the provider when MT_REPAIR_ATTEMPTS is not set
+const DefaultRepairAttempts = 2
+
+// maxAttempts is how often a request is sent before a 429 or 5xx response is returned
+const maxAttempts = 4
+
+// BatchTokens returns the configured token budget per batch
+func BatchTokens() int {
+	if n, err := strconv.Atoi(os.Getenv("MT_BATCH_TOKENS")); err == nil && n > 0 {
+		return n
+	}
+	return DefaultBatchTokens
+}
+
+// Concurrency returns the configured number of parallel batches
+func Concurrency() int {
+	if n, err := strconv.Atoi(os.Getenv("MT_CONCURRENCY")); err == nil && n > 0 {
+		return n
+	}
+	return DefaultConcurrency
+}
+
+// RepairAttempts returns the configured number of repair prompts per batch.
+// 0 disables repairs.
+func RepairAttempts() int {
+	if n, err := strconv.Atoi(os.Getenv("MT_REPAIR_ATTEMPTS")); err == nil && n >= 0 {
+		return n
+	}
+	return DefaultRepairAttempts
+}
+
+// EstimateTokens roughly estimates the tokens of a text: about four ASCII
+// characters per token, and one token per character for other scripts
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return ascii/4 + other + 1
+}
+
+// SplitBatches splits texts into batches whose keys and values stay within the
+// token budget. A single text larger than the budget gets a batch of its own.
+func SplitBatches(texts map[string]string, budget int) []map[string]string {
+	keys := make([]string, 0, len(texts))
+	for k := range texts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var batches []map[string]string
+	current := map[string]string{}
+	tokens := 0
+	for _, k := range keys {
+		n := EstimateTokens(k) + EstimateTokens(texts[k])
+		if len(current) > 0 && tokens+n > budget {
+			batches = append(batches, current)
+			current = map[string]string{}
+			tokens = 0
+		}
+		current[k] = texts[k]
+		tokens += n
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// retryable reports whether a response status is worth retrying
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// doWithRetry sends the request built by newRequest, retrying rate limits and
+// server errors with exponential backoff. A Retry-After header is honored.
+func doWithRetry(ctx context.Context, client *http.Client, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err == nil && (!retryable(resp.StatusCode) || attempt == maxAttempts) {
+			return resp, nil
+		}
+		if err != nil && (ctx.Err() != nil || attempt == maxAttempts) {
+			return nil, err
+		}
+
+		wait := delay + time.Duration(rand.Int63n(int64(delay/2)))
+		if resp != nil {
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				wait = min(time.Duration(seconds)*time.Second, 30*time.Second)
+			}
+			resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,44 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTL is how long machine translations are cached when
+// MT_CACHE_TTL is not set
+const DefaultCacheTTL = 30 * 24 * time.Hour
+
+// CacheTTL returns how long machine translations are reused. 0 disables the cache.
+func CacheTTL() time.Duration {
+	value := os.Getenv("MT_CACHE_TTL")
+	if value == "0" {
+		return 0
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d
+	}
+	return DefaultCacheTTL
+}
+
+// ContextHash hashes what the prompt tells the provider about a text besides
+// the text itself: the style guide, the key's metadata and the glossary terms
+// it contains. Neighbouring keys and examples are left out, so identical
+// strings share cached translations across projects.
+func (r Request) ContextHash(key string) string {
+	texts := map[string]string{key: r.Texts[key]}
+	context := stylePrompt(r.Style, r.TargetLang) + keyContextPrompt(texts, r.Meta) + glossaryPrompt(texts, r.Glossary, r.TargetLang)
+	sum := sha256.Sum256([]byte(context))
+	return hex.EncodeToString(sum[:])
+}
+
+// CacheKey identifies the translation of a request text by a translator, e.g.
+// "openai/gpt-4o", in the machine translation cache
+func (r Request) CacheKey(translator, key string) string {
+	parts := []string{translator, r.SourceLang, r.TargetLang, r.Texts[key], r.ContextHash(key)}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"templui/internal/models"
+)
+
+// DeepL translates with the DeepL API
+type DeepL struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewDeepL creates a DeepL client. Without a base URL the free or pro API is
+// chosen by the key, free keys end in ":fx".
+func NewDeepL(apiKey, baseURL string) *DeepL {
+	if baseURL == "" {
+		baseURL = "https://api.deepl.com"
+		if strings.HasSuffix(apiKey, ":fx") {
+			baseURL = "https://api-free.deepl.com"
+		}
+	}
+	return &DeepL{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Name returns "deepl"
+func (d *DeepL) Name() string {
+	return ProviderDeepL
+}
+
+// Translate translates the texts with XML tag handling, so markup and
+// placeholders are kept. The style guide's formality is passed on.
+func (d *DeepL) Translate(ctx context.Context, req Request) (map[string]string, error) {
+	header := http.Header{"Authorization": {"DeepL-Auth-Key " + d.apiKey}}
+	return translateBatches(req, 50, func(texts []string) ([]string, error) {
+		body := map[string]interface{}{
+			"text":         texts,
+			"source_lang":  strings.ToUpper(primaryLanguage(req.SourceLang)),
+			"target_lang":  deeplTarget(req.TargetLang),
+			"tag_handling": "xml",
+			"ignore_tags":  []string{"x"},
+		}
+		// The prefer_ variants fall back to the default for languages without formality
+		switch req.Style.Formality {
+		case models.FormalityFormal:
+			body["formality"] = "prefer_more"
+		case models.FormalityInformal:
+			body["formality"] = "prefer_less"
+		}
+		var resp struct {
+			Translations []struct {
+				Text string `json:"text"`
+			} `json:"translations"`
+		}
+		if err := postJSON(ctx, d.client, d.baseURL+"/v2/translate", header, body, &resp); err != nil {
+			return nil, err
+		}
+		out := make([]string, len(resp.Translations))
+		for i, t := range resp.Translations {
+			out[i] = t.Text
+		}
+		return out, nil
+	})
+}
+
+// deeplTarget converts a language tag to a DeepL target language. English and
+// Portuguese need a variant, Chinese a script.
+func deeplTarget(tag string) string {
+	upper := strings.ToUpper(strings.ReplaceAll(tag, "_", "-"))
+	switch upper {
+	case "EN":
+		return "EN-US"
+	case "PT":
+		return "PT-PT"
+	case "ZH", "ZH-CN", "ZH-SG":
+		return "ZH-HANS"
+	case "ZH-TW", "ZH-HK":
+		return "ZH-HANT"
+	case "EN-US", "EN-GB", "PT-PT", "PT-BR", "ZH-HANS", "ZH-HANT":
+		return upper
+	}
+	return strings.ToUpper(primaryLanguage(tag))
+}
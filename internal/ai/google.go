@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Google translates with the Google Cloud Translation API (v2)
+type Google struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewGoogle creates a Google Translate client
+func NewGoogle(apiKey string) *Google {
+	return &Google{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Name returns "google"
+func (g *Google) Name() string {
+	return ProviderGoogle
+}
+
+// Translate translates the texts in HTML mode, so markup and placeholders are kept
+func (g *Google) Translate(ctx context.Context, req Request) (map[string]string, error) {
+	endpoint := "https://translation.googleapis.com/language/translate/v2?key=" + url.QueryEscape(g.apiKey)
+	return translateBatches(req, 100, func(texts []string) ([]string, error) {
+		body := map[string]interface{}{
+			"q":      texts,
+			"source": googleLanguage(req.SourceLang),
+			"target": googleLanguage(req.TargetLang),
+			"format": "html",
+		}
+		var resp struct {
+			Data struct {
+				Translations []struct {
+					TranslatedText string `json:"translatedText"`
+				} `json:"translations"`
+			} `json:"data"`
+		}
+		if err := postJSON(ctx, g.client, endpoint, nil, body, &resp); err != nil {
+			return nil, err
+		}
+		out := make([]string, len(resp.Data.Translations))
+		for i, t := range resp.Data.Translations {
+			out[i] = t.TranslatedText
+		}
+		return out, nil
+	})
+}
+
+// googleLanguage converts a language tag to a Google language code. Only
+// Chinese and European Portuguese keep their region.
+func googleLanguage(tag string) string {
+	switch lower := strings.ToLower(strings.ReplaceAll(tag, "_", "-")); {
+	case lower == "zh-tw" || lower == "zh-hk" || lower == "zh-hant":
+		return "zh-TW"
+	case strings.HasPrefix(lower, "zh"):
+		return "zh-CN"
+	case lower == "pt-pt":
+		return "pt-PT"
+	}
+	return primaryLanguage(tag)
+}
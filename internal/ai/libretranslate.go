@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LibreTranslate translates with a LibreTranslate server
+type LibreTranslate struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewLibreTranslate creates a LibreTranslate client. The API key is optional
+// for self-hosted servers.
+func NewLibreTranslate(baseURL, apiKey string) *LibreTranslate {
+	return &LibreTranslate{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// Name returns "libretranslate"
+func (l *LibreTranslate) Name() string {
+	return ProviderLibreTranslate
+}
+
+// Translate translates the texts in HTML mode, so markup and placeholders are kept
+func (l *LibreTranslate) Translate(ctx context.Context, req Request) (map[string]string, error) {
+	return translateBatches(req, 50, func(texts []string) ([]string, error) {
+		body := map[string]interface{}{
+			"q":      texts,
+			"source": primaryLanguage(req.SourceLang),
+			"target": primaryLanguage(req.TargetLang),
+			"format": "html",
+		}
+		if l.apiKey != "" {
+			body["api_key"] = l.apiKey
+		}
+		var resp struct {
+			TranslatedText []string `json:"translatedText"`
+		}
+		if err := postJSON(ctx, l.client, l.baseURL+"/translate", nil, body, &resp); err != nil {
+			return nil, err
+		}
+		return resp.TranslatedText, nil
+	})
+}
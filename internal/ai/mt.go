@@ -0,0 +1,153 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// protectedRegex matches {placeholders} and numbered tags like <0> that machine
+// translation engines would translate or drop
+var protectedRegex = regexp.MustCompile(`\{[^}]+\}|</?\d+\s*/?>`)
+
+// maskRegex matches the protected text and the line breaks, which engines in
+// HTML mode would collapse into spaces
+var maskRegex = regexp.MustCompile(protectedRegex.String() + `|\r?\n`)
+
+// markupRegex matches HTML tags, which engines in HTML or XML mode translate around
+var markupRegex = regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9:_-]*(?:\s+[^<>]*?)?\s*/?>`)
+
+// markerRegex matches the markers protected text is replaced with
+var markerRegex = regexp.MustCompile(`<x id="(\d+)"\s*/>`)
+
+// xmlEscaper escapes the text between markers and tags, so engines in HTML or
+// XML mode read a literal "&amp;" or "a < b" as text
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// xmlUnescaper reverses xmlEscaper and decodes the quotes engines escape in
+// their replies
+var xmlUnescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">",
+	"&quot;", `"`, "&#34;", `"`, "&apos;", "'", "&#39;", "'", "&#x27;", "'")
+
+// maskText replaces protected text and line breaks with <x id="N"/> markers,
+// which engines running in HTML or XML mode keep in place, and XML-escapes
+// the text around them and the markup tags
+func maskText(text string) (string, []string) {
+	var codes []string
+	var sb strings.Builder
+	escape := func(s string) {
+		last := 0
+		for _, loc := range markupRegex.FindAllStringIndex(s, -1) {
+			sb.WriteString(xmlEscaper.Replace(s[last:loc[0]]))
+			sb.WriteString(s[loc[0]:loc[1]])
+			last = loc[1]
+		}
+		sb.WriteString(xmlEscaper.Replace(s[last:]))
+	}
+	last := 0
+	for _, loc := range maskRegex.FindAllStringIndex(text, -1) {
+		escape(text[last:loc[0]])
+		codes = append(codes, text[loc[0]:loc[1]])
+		fmt.Fprintf(&sb, `<x id="%d"/>`, len(codes)-1)
+		last = loc[1]
+	}
+	escape(text[last:])
+	return sb.String(), codes
+}
+
+// unmaskText restores the text replaced by maskText and unescapes the text
+// between the markers
+func unmaskText(text string, codes []string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range markerRegex.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(xmlUnescaper.Replace(text[last:loc[0]]))
+		i, err := strconv.Atoi(text[loc[2]:loc[3]])
+		if err != nil || i >= len(codes) {
+			sb.WriteString(text[loc[0]:loc[1]])
+		} else {
+			sb.WriteString(codes[i])
+		}
+		last = loc[1]
+	}
+	sb.WriteString(xmlUnescaper.Replace(text[last:]))
+	return sb.String()
+}
+
+// translateBatches masks the request texts, translates them in batches of
+// batchSize with fn and restores the protected text in the results
+func translateBatches(req Request, batchSize int, fn func(texts []string) ([]string, error)) (map[string]string, error) {
+	texts := req.nonEmpty()
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts to translate")
+	}
+	keys := make([]string, 0, len(texts))
+	for k := range texts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]string, len(keys))
+	for start := 0; start < len(keys); start += batchSize {
+		batch := keys[start:min(start+batchSize, len(keys))]
+		masked := make([]string, len(batch))
+		codes := make([][]string, len(batch))
+		for i, k := range batch {
+			masked[i], codes[i] = maskText(texts[k])
+		}
+
+		translated, err := fn(masked)
+		if err != nil {
+			return nil, err
+		}
+		if len(translated) != len(batch) {
+			return nil, fmt.Errorf("expected %d translations, got %d", len(batch), len(translated))
+		}
+		for i, k := range batch {
+			result[k] = unmaskText(translated[i], codes[i])
+		}
+	}
+	return result, nil
+}
+
+// postJSON sends a JSON request and decodes the JSON response into out
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body, out interface{}) error {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := doWithRetry(ctx, client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errBody bytes.Buffer
+		errBody.ReadFrom(resp.Body)
+		return fmt.Errorf("api error: status %d - %s", resp.StatusCode, errBody.String())
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// primaryLanguage returns the primary subtag of a language tag, e.g. "pt" for "pt-BR"
+func primaryLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	return strings.ToLower(primary)
+}
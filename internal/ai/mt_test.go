@@ -0,0 +1,70 @@
+package ai
+
+import "testing"
+
+func TestMaskText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		masked string
+		reply  string // What an engine in HTML or XML mode returns
+		want   string
+	}{
+		{
+			name:   "placeholders",
+			text:   "Hello {name}",
+			masked: `Hello <x id="0"/>`,
+			reply:  `Hallo <x id="0"/>`,
+			want:   "Hallo {name}",
+		},
+		{
+			name:   "literal entities stay literal",
+			text:   "Use &amp; or &lt;",
+			masked: "Use &amp;amp; or &amp;lt;",
+			reply:  "Verwende &amp;amp; oder &amp;lt;",
+			want:   "Verwende &amp; oder &lt;",
+		},
+		{
+			name:   "markup is kept, stray brackets escaped",
+			text:   `A & B < C <a href="/x">x</a> <1>y</1>`,
+			masked: `A &amp; B &lt; C <a href="/x">x</a> <x id="0"/>y<x id="1"/>`,
+			reply:  `A &amp; B &lt; C <a href="/x">x</a> <x id="0"/>y<x id="1"/>`,
+			want:   `A & B < C <a href="/x">x</a> <1>y</1>`,
+		},
+		{
+			name:   "line breaks",
+			text:   "First\nSecond",
+			masked: `First<x id="0"/>Second`,
+			reply:  `Erste <x id="0"/> Zweite`,
+			want:   "Erste \n Zweite",
+		},
+		{
+			name:   "escaped quotes in the reply",
+			text:   "It's",
+			masked: "It's",
+			reply:  "C&#39;est &quot;ok&quot;",
+			want:   `C'est "ok"`,
+		},
+		{
+			name:   "unknown marker is kept",
+			text:   "{a}",
+			masked: `<x id="0"/>`,
+			reply:  `<x id="0"/><x id="5"/>`,
+			want:   `{a}<x id="5"/>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			masked, codes := maskText(tt.text)
+			if masked != tt.masked {
+				t.Errorf("maskText(%q) = %q, want %q", tt.text, masked, tt.masked)
+			}
+			if got := unmaskText(masked, codes); got != tt.text {
+				t.Errorf("unmaskText(maskText(%q)) = %q", tt.text, got)
+			}
+			if got := unmaskText(tt.reply, codes); got != tt.want {
+				t.Errorf("unmaskText(%q) = %q, want %q", tt.reply, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,38 +2,49 @@ package ai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"templui/internal/models"
 )
 
+// DefaultModel is the chat model used for translations when none is configured
+const DefaultModel = "gpt-4o"
+
+// DefaultOpenAIURL is the base URL of the OpenAI API
+const DefaultOpenAIURL = "https://api.openai.com/v1"
+
+// OpenAIClient translates with an OpenAI-compatible chat completions endpoint.
+// It is also used for Ollama, which serves the same API.
 type OpenAIClient struct {
-	apiKey string
-	client *http.Client
-}
-
-func NewOpenAIClient() *OpenAIClient {
-	fmt.Println("Creating OpenAI client")
-	key := os.Getenv("OPENAI_API_KEY")
-	if key == "" {
-		fmt.Println("WARNING: OPENAI_API_KEY is not set")
-	} else {
-		// Log masked key for debugging
-		masked := ""
-		if len(key) > 8 {
-			masked = key[:4] + "..." + key[len(key)-4:]
-		} else {
-			masked = "***"
-		}
-		fmt.Printf("OPENAI_API_KEY is set (%s)\n", masked)
-	}
+	provider string
+	baseURL  string
+	apiKey   string
+	model    string
+	client   *http.Client
+}
+
+// NewOpenAIClient creates a client for an OpenAI-compatible endpoint
+func NewOpenAIClient(provider, baseURL, apiKey, model string) *OpenAIClient {
 	return &OpenAIClient{
-		apiKey: key,
-		client: &http.Client{},
+		provider: provider,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		apiKey:   apiKey,
+		model:    model,
+		client:   &http.Client{Timeout: 5 * time.Minute},
 	}
 }
 
+// Name returns the provider and model, e.g. "openai/gpt-4o"
+func (g *OpenAIClient) Name() string {
+	return g.provider + "/" + g.model
+}
+
 type ChatRequest struct {
 	Model          string         `json:"model"`
 	Messages       []Message      `json:"messages"`
@@ -53,82 +64,238 @@ type ChatResponse struct {
 	Choices []struct {
 		Message Message `json:"message"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
 }
 
-func (g *OpenAIClient) Translate(baseLang, targetLang string, texts map[string]string) (map[string]string, error) {
-	if g.apiKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY is not set")
-	}
-
-	// Only send keys that have values
-	toTranslate := make(map[string]string)
-	for k, v := range texts {
-		if v != "" {
-			toTranslate[k] = v
-		}
-	}
-
+// messages builds the chat messages for a request. The system message carries
+// the instructions and context, the user message the texts as JSON.
+func (g *OpenAIClient) messages(req Request) ([]Message, error) {
+	toTranslate := req.nonEmpty()
 	if len(toTranslate) == 0 {
 		return nil, fmt.Errorf("no texts to translate")
 	}
 
 	prompt := fmt.Sprintf(`You are a professional translator. Translate the following JSON key-value pairs from %s to %s. 
 Return ONLY valid JSON with the same keys and translated values. Do not translate the keys.
-Preserve any placeholders like {name}, {count}, etc. as is.`, baseLang, targetLang)
+Preserve any placeholders like {name}, {count}, etc. as is.`, req.SourceLang, req.TargetLang)
+	prompt += stylePrompt(req.Style, req.TargetLang)
+	prompt += keyContextPrompt(toTranslate, req.Meta)
+	prompt += glossaryPrompt(toTranslate, req.Glossary, req.TargetLang)
+	prompt += neighboursPrompt(toTranslate, req.Existing)
+	prompt += examplesPrompt(toTranslate, req.Existing)
+	prompt += repairPrompt(toTranslate, req.Repairs)
 
 	inputJSON, err := json.Marshal(toTranslate)
 	if err != nil {
 		return nil, err
 	}
+	return []Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: string(inputJSON)},
+	}, nil
+}
 
-	reqBody := ChatRequest{
-		Model: "gpt-4o",
-		Messages: []Message{
-			{Role: "system", Content: prompt},
-			{Role: "user", Content: string(inputJSON)},
-		},
-		ResponseFormat: ResponseFormat{Type: "json_object"},
+// Prompt returns the messages sent for a request as text
+func (g *OpenAIClient) Prompt(req Request) string {
+	messages, err := g.messages(req)
+	if err != nil {
+		return ""
+	}
+	parts := make([]string, len(messages))
+	for i, m := range messages {
+		parts[i] = m.Role + ":\n" + m.Content
 	}
+	return strings.Join(parts, "\n\n")
+}
 
-	jsonBody, err := json.Marshal(reqBody)
+// Translate translates the given key-value pairs. The prompt describes the
+// project's style guide, key metadata, glossary terms, neighbouring keys and
+// approved translations so the model translates consistently with the project.
+func (g *OpenAIClient) Translate(ctx context.Context, req Request) (map[string]string, error) {
+	result, _, err := g.TranslateWithUsage(ctx, req)
+	return result, err
+}
+
+// TranslateWithUsage is Translate that also returns the token usage reported by the API
+func (g *OpenAIClient) TranslateWithUsage(ctx context.Context, req Request) (map[string]string, Usage, error) {
+	messages, err := g.messages(req)
 	if err != nil {
-		return nil, err
+		return nil, Usage{}, err
 	}
-
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonBody))
+	content, usage, err := g.chat(ctx, messages)
 	if err != nil {
-		return nil, err
+		return nil, usage, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+g.apiKey)
-	req.Header.Set("Content-Type", "application/json")
+	var result map[string]string
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, usage, err
+	}
+	return result, usage, nil
+}
 
-	resp, err := g.client.Do(req)
+// chat sends messages to the chat completions endpoint in JSON mode and
+// returns the content of the reply with the token usage
+func (g *OpenAIClient) chat(ctx context.Context, messages []Message) (string, Usage, error) {
+	var usage Usage
+	reqBody := ChatRequest{
+		Model:          g.model,
+		Messages:       messages,
+		ResponseFormat: ResponseFormat{Type: "json_object"},
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, err
+		return "", usage, err
+	}
+
+	resp, err := doWithRetry(ctx, g.client, func() (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", g.baseURL+"/chat/completions", bytes.NewReader(jsonBody))
+		if err != nil {
+			return nil, err
+		}
+		if g.apiKey != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+g.apiKey)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		return httpReq, nil
+	})
+	if err != nil {
+		return "", usage, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		var errBody bytes.Buffer
 		errBody.ReadFrom(resp.Body)
-		fmt.Printf("OpenAI API Error Body: %s\n", errBody.String())
-		return nil, fmt.Errorf("openai api error: status %d - %s", resp.StatusCode, errBody.String())
+		return "", usage, fmt.Errorf("%s api error: status %d - %s", g.provider, resp.StatusCode, errBody.String())
 	}
 
 	var chatResp ChatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return nil, err
+		return "", usage, err
 	}
 
+	usage.PromptTokens = chatResp.Usage.PromptTokens
+	usage.CompletionTokens = chatResp.Usage.CompletionTokens
+
 	if len(chatResp.Choices) == 0 {
-		return nil, fmt.Errorf("no translation returned")
+		return "", usage, fmt.Errorf("no translation returned")
 	}
+	return chatResp.Choices[0].Message.Content, usage, nil
+}
 
-	var result map[string]string
-	if err := json.Unmarshal([]byte(chatResp.Choices[0].Message.Content), &result); err != nil {
-		return nil, err
+// keyContextPrompt describes the keys that have metadata so the model can use it
+func keyContextPrompt(texts map[string]string, meta map[string]models.KeyMetadata) string {
+	keys := make([]string, 0, len(texts))
+	for k := range texts {
+		if m, ok := meta[k]; ok && !m.IsEmpty() {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("\n\nContext for specific keys:")
+	for _, k := range keys {
+		m := meta[k]
+		var parts []string
+		if m.Description != "" {
+			parts = append(parts, "meaning: "+m.Description)
+		}
+		if m.Context != "" {
+			parts = append(parts, "appears in: "+m.Context)
+		}
+		if m.MaxLength > 0 {
+			parts = append(parts, fmt.Sprintf("at most %d characters", m.MaxLength))
+		}
+		if len(m.Tags) > 0 {
+			parts = append(parts, "tags: "+strings.Join(m.Tags, ", "))
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n- %s: %s", k, strings.Join(parts, "; ")))
+	}
+	return sb.String()
+}
+
+// glossaryPrompt lists the glossary terms that occur in the texts with their
+// required translations
+func glossaryPrompt(texts map[string]string, glossary []models.GlossaryTerm, targetLang string) string {
+	var lines []string
+	for _, t := range glossary {
+		if !glossaryTermUsed(texts, t) {
+			continue
+		}
+		tr := t.Translations[targetLang]
+		var line string
+		switch {
+		case t.DoNotTranslate:
+			line = fmt.Sprintf("- %q: never translate, keep it exactly as is", t.Term)
+		case tr.Translation != "":
+			line = fmt.Sprintf("- %q: always translate as %q", t.Term, tr.Translation)
+		default:
+			continue
+		}
+		if len(tr.Forbidden) > 0 && !t.DoNotTranslate {
+			line += fmt.Sprintf(", never as %s", strings.Join(quoteAll(tr.Forbidden), " or "))
+		}
+		if t.Note != "" {
+			line += " (" + t.Note + ")"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\nGlossary, follow it strictly:\n" + strings.Join(lines, "\n")
+}
+
+// repairPrompt lists earlier translations that failed validation with the
+// problem found, so the model corrects them instead of repeating them
+func repairPrompt(texts map[string]string, repairs map[string]Repair) string {
+	keys := make([]string, 0, len(repairs))
+	for k := range repairs {
+		if _, ok := texts[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
 	}
+	sort.Strings(keys)
 
-	return result, nil
+	var sb strings.Builder
+	sb.WriteString("\n\nYour previous translations of these keys were rejected. Translate them again and fix the problem:")
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("\n- %s: %q was rejected: %s", k, repairs[k].Previous, repairs[k].Problem))
+	}
+	return sb.String()
+}
+
+func glossaryTermUsed(texts map[string]string, t models.GlossaryTerm) bool {
+	for _, text := range texts {
+		if t.CaseSensitive && strings.Contains(text, t.Term) {
+			return true
+		}
+		if !t.CaseSensitive && strings.Contains(strings.ToLower(text), strings.ToLower(t.Term)) {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
 }
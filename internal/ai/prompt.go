@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"templui/internal/models"
+)
+
+// maxNeighbours is how many translated keys of the same namespaces are shown
+// to the model for context
+const maxNeighbours = 20
+
+// maxExamples is how many approved translations are shown as examples
+const maxExamples = 5
+
+// formalForms are the formal and informal forms of address of languages that
+// distinguish them
+var formalForms = map[string][2]string{
+	"de": {"Sie", "du"},
+	"fr": {"vous", "tu"},
+	"es": {"usted", "tú"},
+	"it": {"Lei", "tu"},
+	"nl": {"u", "je"},
+	"fi": {"te", "sinä"},
+	"sv": {"ni", "du"},
+	"da": {"De", "du"},
+	"nb": {"De", "du"},
+	"pl": {"Pan/Pani", "ty"},
+	"cs": {"vy", "ty"},
+	"ru": {"вы", "ты"},
+	"uk": {"ви", "ти"},
+	"tr": {"siz", "sen"},
+	"pt": {"o senhor/a senhora", "você"},
+}
+
+// stylePrompt describes the project's style guide
+func stylePrompt(style models.StyleGuide, targetLang string) string {
+	if style.IsEmpty() {
+		return ""
+	}
+
+	var lines []string
+	forms, known := formalForms[primaryLanguage(targetLang)]
+	switch style.Formality {
+	case models.FormalityFormal:
+		line := "- Address the reader formally"
+		if known {
+			line += fmt.Sprintf(" (use %q, not %q)", forms[0], forms[1])
+		}
+		lines = append(lines, line+".")
+	case models.FormalityInformal:
+		line := "- Address the reader informally"
+		if known {
+			line += fmt.Sprintf(" (use %q, not %q)", forms[1], forms[0])
+		}
+		lines = append(lines, line+".")
+	}
+	if style.Tone != "" {
+		lines = append(lines, "- Tone: "+style.Tone)
+	}
+	if style.Audience != "" {
+		lines = append(lines, "- Audience: "+style.Audience)
+	}
+	if style.Notes != "" {
+		lines = append(lines, "- "+style.Notes)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\nStyle guide:\n" + strings.Join(lines, "\n")
+}
+
+// keyNamespace returns the part of a key before its last dot, e.g.
+// "settings.profile" for "settings.profile.title"
+func keyNamespace(key string) string {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[:i]
+	}
+	return ""
+}
+
+// neighbourKeys returns the sorted keys of translated segments that share a
+// namespace with the texts, up to limit
+func neighbourKeys(texts map[string]string, existing map[string]Segment, limit int) []string {
+	namespaces := make(map[string]bool)
+	for k := range texts {
+		namespaces[keyNamespace(k)] = true
+	}
+	var keys []string
+	for k, seg := range existing {
+		if _, ok := texts[k]; ok || seg.Target == "" || !namespaces[keyNamespace(k)] {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys[:min(len(keys), limit)]
+}
+
+// neighboursPrompt shows translated keys next to the texts, so the model sees
+// the surrounding UI and keeps the wording consistent
+func neighboursPrompt(texts map[string]string, existing map[string]Segment) string {
+	keys := neighbourKeys(texts, existing, maxNeighbours)
+	if len(keys) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\nExisting translations of neighbouring keys, for context (do not return them):")
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("\n- %s: %q -> %q", k, existing[k].Source, existing[k].Target))
+	}
+	return sb.String()
+}
+
+// examplesPrompt shows approved translations of the project as examples of
+// the expected style. Examples from the texts' namespaces are preferred.
+func examplesPrompt(texts map[string]string, existing map[string]Segment) string {
+	shown := make(map[string]bool)
+	for _, k := range neighbourKeys(texts, existing, maxNeighbours) {
+		shown[k] = true
+	}
+	namespaces := make(map[string]bool)
+	for k := range texts {
+		namespaces[keyNamespace(k)] = true
+	}
+
+	var keys []string
+	for k, seg := range existing {
+		if _, ok := texts[k]; ok || shown[k] || !seg.Approved || seg.Target == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, nj := namespaces[keyNamespace(keys[i])], namespaces[keyNamespace(keys[j])]
+		if ni != nj {
+			return ni
+		}
+		return keys[i] < keys[j]
+	})
+	keys = keys[:min(len(keys), maxExamples)]
+
+	var sb strings.Builder
+	sb.WriteString("\n\nApproved translations from this project, follow their style:")
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("\n- %q -> %q", existing[k].Source, existing[k].Target))
+	}
+	return sb.String()
+}
@@ -0,0 +1,241 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"templui/internal/models"
+)
+
+// QualityRequest asks for a review of existing translations
+type QualityRequest struct {
+	SourceLang    string
+	TargetLang    string
+	Segments      map[string]Segment // Key -> source text and translation
+	Style         models.StyleGuide
+	Score         bool // Rate each translation and list its issues
+	BackTranslate bool // Translate each translation back into the source language
+}
+
+// Assessment is the review of one translation
+type Assessment struct {
+	Score           *int                  `json:"score"`
+	Issues          []models.QualityIssue `json:"issues"`
+	BackTranslation string                `json:"back_translation"`
+}
+
+// QualityEstimator is implemented by translators that can review translations.
+// Only the LLM providers can.
+type QualityEstimator interface {
+	EstimateQuality(ctx context.Context, req QualityRequest) (map[string]Assessment, Usage, error)
+	Name() string
+}
+
+// QualityResult is the outcome of a quality request
+type QualityResult struct {
+	Assessments map[string]Assessment
+	Translator  string // Name of the translator that reviewed
+	Usage       Usage
+}
+
+// Cost returns the cost of the request in USD
+func (r *QualityResult) Cost() float64 {
+	return r.Usage.Cost(r.Translator)
+}
+
+// qualityIssueTypes are the issue types the model may report
+var qualityIssueTypes = []string{
+	models.QualityMeaning, models.QualityFormality, models.QualityGrammar, models.QualityTerminology, models.QualityOther,
+}
+
+// qualityEstimator returns the first provider that can review translations
+func qualityEstimator(providers []models.MTProvider) (QualityEstimator, error) {
+	if len(providers) == 0 {
+		providers = DefaultProviders()
+	}
+	var errs []error
+	for _, p := range providers {
+		translator, err := New(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Provider, err))
+			continue
+		}
+		if qe, ok := translator.(QualityEstimator); ok {
+			return qe, nil
+		}
+	}
+	errs = append(errs, errors.New("no configured provider supports quality estimation, add an LLM provider (openai or ollama)"))
+	return nil, errors.Join(errs...)
+}
+
+// QualityTranslatorName returns the name of the translator that would review
+// translations for the providers, for budget checks
+func QualityTranslatorName(providers []models.MTProvider) (string, error) {
+	qe, err := qualityEstimator(providers)
+	if err != nil {
+		return "", err
+	}
+	return qe.Name(), nil
+}
+
+// EstimateQuality reviews translations with the first of the providers that
+// supports quality estimation
+func EstimateQuality(ctx context.Context, providers []models.MTProvider, req QualityRequest) (*QualityResult, error) {
+	qe, err := qualityEstimator(providers)
+	if err != nil {
+		return nil, err
+	}
+	assessments, usage, err := qe.EstimateQuality(ctx, req)
+	if err != nil {
+		// The usage of a failed review is returned too, the provider bills it
+		return &QualityResult{Translator: qe.Name(), Usage: usage}, fmt.Errorf("%s: %w", qe.Name(), err)
+	}
+	if usage == (Usage{}) {
+		usage = EstimateQualityUsage(req)
+	}
+	return &QualityResult{Assessments: assessments, Translator: qe.Name(), Usage: usage}, nil
+}
+
+// EstimateQualityUsage estimates the tokens of a quality request before it is sent
+func EstimateQualityUsage(req QualityRequest) Usage {
+	var u Usage
+	if req.Score {
+		u.PromptTokens += promptOverhead
+		for k, s := range req.Segments {
+			u.PromptTokens += EstimateTokens(k) + EstimateTokens(s.Source) + EstimateTokens(s.Target)
+			u.CompletionTokens += EstimateTokens(k) + 30 // Score and issues
+		}
+	}
+	if req.BackTranslate {
+		u.PromptTokens += promptOverhead
+		for k, s := range req.Segments {
+			u.PromptTokens += EstimateTokens(k) + EstimateTokens(s.Target)
+			u.CompletionTokens += EstimateTokens(k) + EstimateTokens(s.Source)
+		}
+	}
+	return u
+}
+
+// qualityMessages builds the chat messages that score translations against
+// their source
+func qualityMessages(req QualityRequest) ([]Message, error) {
+	if len(req.Segments) == 0 {
+		return nil, fmt.Errorf("no translations to review")
+	}
+	prompt := fmt.Sprintf(`You are a professional translation reviewer. You get JSON with keys mapped to a "source" text in %s and its "translation" in %s.
+Return ONLY valid JSON that maps every key to an object with these fields:
+- "score": how good the translation is, from 0 (wrong or unusable) to 100 (perfect).
+- "issues": the problems found, each {"type": one of %s, "message": a short explanation in English}. Use an empty list when there are none.
+Placeholders like {name} and markup are expected to be kept as is.`,
+		req.SourceLang, req.TargetLang, strings.Join(quoteAll(qualityIssueTypes), ", "))
+	prompt += stylePrompt(req.Style, req.TargetLang)
+
+	type pair struct {
+		Source      string `json:"source"`
+		Translation string `json:"translation"`
+	}
+	pairs := make(map[string]pair, len(req.Segments))
+	for k, s := range req.Segments {
+		pairs[k] = pair{Source: s.Source, Translation: s.Target}
+	}
+	inputJSON, err := json.Marshal(pairs)
+	if err != nil {
+		return nil, err
+	}
+	return []Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: string(inputJSON)},
+	}, nil
+}
+
+// backTranslationMessages builds the chat messages that translate translations
+// back into the source language. The source texts are left out so the model
+// cannot copy their meaning and hide drift.
+func backTranslationMessages(req QualityRequest) ([]Message, error) {
+	if len(req.Segments) == 0 {
+		return nil, fmt.Errorf("no translations to review")
+	}
+	prompt := fmt.Sprintf(`You are a professional translator. You get JSON with keys mapped to texts in %s.
+Translate every text into %s as literally as possible, so a reader can check what it says, even where it sounds odd or seems wrong.
+Keep placeholders like {name} and markup as they are.
+Return ONLY valid JSON that maps every key to its translation.`, req.TargetLang, req.SourceLang)
+
+	texts := make(map[string]string, len(req.Segments))
+	for k, s := range req.Segments {
+		texts[k] = s.Target
+	}
+	inputJSON, err := json.Marshal(texts)
+	if err != nil {
+		return nil, err
+	}
+	return []Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: string(inputJSON)},
+	}, nil
+}
+
+// EstimateQuality reviews translations with the chat model. Scores and
+// back-translations are separate requests, see backTranslationMessages.
+func (g *OpenAIClient) EstimateQuality(ctx context.Context, req QualityRequest) (map[string]Assessment, Usage, error) {
+	result := make(map[string]Assessment, len(req.Segments))
+	var usage Usage
+	if req.Score {
+		messages, err := qualityMessages(req)
+		if err != nil {
+			return nil, usage, err
+		}
+		content, u, err := g.chat(ctx, messages)
+		usage = usage.Add(u)
+		if err != nil {
+			return nil, usage, err
+		}
+		var scored map[string]Assessment
+		if err := json.Unmarshal([]byte(content), &scored); err != nil {
+			return nil, usage, err
+		}
+		for k, a := range scored {
+			if _, ok := req.Segments[k]; !ok {
+				continue
+			}
+			if a.Score != nil {
+				score := min(max(*a.Score, 0), 100)
+				a.Score = &score
+			}
+			for i, issue := range a.Issues {
+				if !slices.Contains(qualityIssueTypes, issue.Type) {
+					a.Issues[i].Type = models.QualityOther
+				}
+			}
+			a.BackTranslation = ""
+			result[k] = a
+		}
+	}
+	if req.BackTranslate {
+		messages, err := backTranslationMessages(req)
+		if err != nil {
+			return nil, usage, err
+		}
+		content, u, err := g.chat(ctx, messages)
+		usage = usage.Add(u)
+		if err != nil {
+			return nil, usage, err
+		}
+		var back map[string]string
+		if err := json.Unmarshal([]byte(content), &back); err != nil {
+			return nil, usage, err
+		}
+		for k, text := range back {
+			if _, ok := req.Segments[k]; !ok {
+				continue
+			}
+			a := result[k]
+			a.BackTranslation = text
+			result[k] = a
+		}
+	}
+	return result, usage, nil
+}
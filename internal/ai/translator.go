@@ -0,0 +1,265 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"templui/internal/models"
+)
+
+// Supported machine translation providers
+const (
+	ProviderOpenAI         = "openai"
+	ProviderDeepL          = "deepl"
+	ProviderGoogle         = "google"
+	ProviderLibreTranslate = "libretranslate"
+	ProviderOllama         = "ollama"
+)
+
+// Providers lists the supported providers
+var Providers = []string{ProviderOpenAI, ProviderDeepL, ProviderGoogle, ProviderLibreTranslate, ProviderOllama}
+
+// Request is a set of strings to translate from one language to another
+type Request struct {
+	SourceLang string
+	TargetLang string
+	Texts      map[string]string // key -> source text
+	Meta       map[string]models.KeyMetadata
+	Glossary   []models.GlossaryTerm
+	Style      models.StyleGuide
+	Existing   map[string]Segment // Translated keys of the file, for neighbouring keys and examples
+	Repairs    map[string]Repair  // Earlier invalid translations of keys, by key
+}
+
+// Segment is a source text and its existing translation
+type Segment struct {
+	Source   string
+	Target   string
+	Approved bool
+}
+
+// Repair is a translation that failed validation, sent back to the provider
+// with the problem so it can correct it. Only the LLM providers use it.
+type Repair struct {
+	Previous string
+	Problem  string
+}
+
+// nonEmpty returns the texts that have a value
+func (r Request) nonEmpty() map[string]string {
+	texts := make(map[string]string, len(r.Texts))
+	for k, v := range r.Texts {
+		if v != "" {
+			texts[k] = v
+		}
+	}
+	return texts
+}
+
+// Translator translates strings with a machine translation provider
+type Translator interface {
+	// Translate returns the translations of the request texts by key
+	Translate(ctx context.Context, req Request) (map[string]string, error)
+	// Name identifies the provider and model, and is recorded as the author of its translations
+	Name() string
+}
+
+// Prompter is implemented by translators that send a prompt, so the prompt
+// can be recorded with their translations
+type Prompter interface {
+	Prompt(req Request) string
+}
+
+// Result holds the translations of a request and how they were made
+type Result struct {
+	Translations map[string]string
+	Translator   string            // Name of the translator that succeeded
+	Provider     models.MTProvider // Provider of that translator
+	Prompt       string            // Prompt sent to an LLM provider, empty for other providers
+	Usage        Usage
+	// Attempts is the usage of every provider that was tried, including
+	// failed attempts that were billed, such as replies that were not valid JSON
+	Attempts []Attempt
+}
+
+// Attempt is the usage of one provider request
+type Attempt struct {
+	Translator string
+	Usage      Usage
+}
+
+// Cost returns the cost of the request in USD
+func (r *Result) Cost() float64 {
+	return r.Usage.Cost(r.Translator)
+}
+
+// ProviderInfo describes a provider and whether this installation configured it
+type ProviderInfo struct {
+	Name         string `json:"name"`
+	Configured   bool   `json:"configured"`
+	DefaultModel string `json:"default_model,omitempty"`
+}
+
+// AvailableProviders lists all providers with their configuration state
+func AvailableProviders() []ProviderInfo {
+	infos := make([]ProviderInfo, 0, len(Providers))
+	for _, name := range Providers {
+		_, err := New(models.MTProvider{Provider: name})
+		infos = append(infos, ProviderInfo{
+			Name:         name,
+			Configured:   err == nil,
+			DefaultModel: defaultModel(name),
+		})
+	}
+	return infos
+}
+
+// IsProvider reports whether name is a supported provider
+func IsProvider(name string) bool {
+	for _, p := range Providers {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultProviders returns the providers used by projects without settings:
+// MT_PROVIDER (default "openai") with its default model
+func DefaultProviders() []models.MTProvider {
+	provider := os.Getenv("MT_PROVIDER")
+	if provider == "" {
+		provider = ProviderOpenAI
+	}
+	return []models.MTProvider{{Provider: provider}}
+}
+
+func defaultModel(provider string) string {
+	switch provider {
+	case ProviderOpenAI:
+		if model := os.Getenv("OPENAI_MODEL"); model != "" {
+			return model
+		}
+		return DefaultModel
+	case ProviderOllama:
+		if model := os.Getenv("OLLAMA_MODEL"); model != "" {
+			return model
+		}
+		return "llama3.1"
+	}
+	return ""
+}
+
+// New creates the translator for a provider, configured from the environment.
+// The model only applies to the LLM providers (openai and ollama).
+func New(p models.MTProvider) (Translator, error) {
+	model := p.Model
+	if model == "" {
+		model = defaultModel(p.Provider)
+	}
+
+	switch p.Provider {
+	case ProviderOpenAI:
+		baseURL := os.Getenv("OPENAI_BASE_URL")
+		key := os.Getenv("OPENAI_API_KEY")
+		// Self-hosted OpenAI-compatible servers often need no key
+		if key == "" && (baseURL == "" || baseURL == DefaultOpenAIURL) {
+			return nil, fmt.Errorf("OPENAI_API_KEY is not set")
+		}
+		if baseURL == "" {
+			baseURL = DefaultOpenAIURL
+		}
+		return NewOpenAIClient(ProviderOpenAI, baseURL, key, model), nil
+	case ProviderOllama:
+		baseURL := os.Getenv("OLLAMA_URL")
+		if baseURL == "" {
+			return nil, fmt.Errorf("OLLAMA_URL is not set")
+		}
+		return NewOpenAIClient(ProviderOllama, strings.TrimSuffix(baseURL, "/")+"/v1", "", model), nil
+	case ProviderDeepL:
+		key := os.Getenv("DEEPL_API_KEY")
+		if key == "" {
+			return nil, fmt.Errorf("DEEPL_API_KEY is not set")
+		}
+		return NewDeepL(key, os.Getenv("DEEPL_BASE_URL")), nil
+	case ProviderGoogle:
+		key := os.Getenv("GOOGLE_TRANSLATE_API_KEY")
+		if key == "" {
+			return nil, fmt.Errorf("GOOGLE_TRANSLATE_API_KEY is not set")
+		}
+		return NewGoogle(key), nil
+	case ProviderLibreTranslate:
+		baseURL := os.Getenv("LIBRETRANSLATE_URL")
+		if baseURL == "" {
+			return nil, fmt.Errorf("LIBRETRANSLATE_URL is not set")
+		}
+		return NewLibreTranslate(baseURL, os.Getenv("LIBRETRANSLATE_API_KEY")), nil
+	default:
+		return nil, fmt.Errorf("unknown provider: %s", p.Provider)
+	}
+}
+
+// Translate tries the providers in order and returns the translations of the
+// first one that succeeds. The result is returned with an error too, with the
+// attempts that were billed.
+func Translate(ctx context.Context, providers []models.MTProvider, req Request) (*Result, error) {
+	if len(providers) == 0 {
+		providers = DefaultProviders()
+	}
+
+	var errs []error
+	var attempts []Attempt
+	for _, p := range providers {
+		translator, err := New(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Provider, err))
+			continue
+		}
+		var translations map[string]string
+		var usage Usage
+		if ut, ok := translator.(UsageTranslator); ok {
+			translations, usage, err = ut.TranslateWithUsage(ctx, req)
+		} else {
+			translations, err = translator.Translate(ctx, req)
+		}
+		if err != nil {
+			if usage != (Usage{}) {
+				attempts = append(attempts, Attempt{Translator: translator.Name(), Usage: usage})
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", translator.Name(), err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		// Providers that report no usage are billed by the estimate
+		if usage == (Usage{}) {
+			usage = EstimateUsage(translator.Name(), req)
+		}
+		attempts = append(attempts, Attempt{Translator: translator.Name(), Usage: usage})
+		result := &Result{Translations: translations, Translator: translator.Name(), Provider: p, Usage: usage, Attempts: attempts}
+		if prompter, ok := translator.(Prompter); ok {
+			result.Prompt = prompter.Prompt(req)
+		}
+		return result, nil
+	}
+	return &Result{Attempts: attempts}, errors.Join(errs...)
+}
+
+// EstimateCost returns the estimated cost of a request for budget checks. A
+// request can fall back to any of the providers, so the most expensive one is
+// used.
+func EstimateCost(providers []models.MTProvider, req Request) float64 {
+	if len(providers) == 0 {
+		providers = DefaultProviders()
+	}
+	var cost float64
+	for _, p := range providers {
+		translator := TranslatorName(p)
+		cost = max(cost, EstimateUsage(translator, req).Cost(translator))
+	}
+	return cost
+}
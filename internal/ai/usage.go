@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"templui/internal/models"
+)
+
+// Usage is what a translation request consumed. LLM providers report tokens,
+// the other providers bill by source characters.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	Characters       int `json:"characters"`
+}
+
+// Add returns the sum of two usages
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + o.PromptTokens,
+		CompletionTokens: u.CompletionTokens + o.CompletionTokens,
+		Characters:       u.Characters + o.Characters,
+	}
+}
+
+// UsageTranslator is implemented by translators whose API reports usage
+type UsageTranslator interface {
+	TranslateWithUsage(ctx context.Context, req Request) (map[string]string, Usage, error)
+}
+
+// Price is the cost in USD per million units: input and output tokens for
+// LLMs, characters (as Input) for the other providers
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// prices are the list prices of common models and providers. AI_PRICES
+// overrides or adds entries.
+var prices = map[string]Price{
+	"openai/gpt-4o":        {Input: 2.50, Output: 10.00},
+	"openai/gpt-4o-mini":   {Input: 0.15, Output: 0.60},
+	"openai/gpt-4.1":       {Input: 2.00, Output: 8.00},
+	"openai/gpt-4.1-mini":  {Input: 0.40, Output: 1.60},
+	"openai/gpt-4.1-nano":  {Input: 0.10, Output: 0.40},
+	ProviderDeepL:          {Input: 25.00},
+	ProviderGoogle:         {Input: 20.00},
+	ProviderLibreTranslate: {},
+}
+
+// PriceOf returns the price of a translator by name, e.g. "openai/gpt-4o".
+// Ollama runs locally and is free; unknown models are priced like the default model.
+func PriceOf(translator string) Price {
+	if p, ok := configuredPrices()[translator]; ok {
+		return p
+	}
+	if p, ok := prices[translator]; ok {
+		return p
+	}
+	if strings.HasPrefix(translator, ProviderOllama+"/") {
+		return Price{}
+	}
+	return prices[ProviderOpenAI+"/"+DefaultModel]
+}
+
+// configuredPrices parses AI_PRICES, e.g. "openai/my-model=0.5/1.5,deepl=20"
+func configuredPrices() map[string]Price {
+	configured := make(map[string]Price)
+	for _, entry := range strings.Split(os.Getenv("AI_PRICES"), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		in, out, _ := strings.Cut(value, "/")
+		var p Price
+		p.Input, _ = strconv.ParseFloat(in, 64)
+		p.Output, _ = strconv.ParseFloat(out, 64)
+		configured[name] = p
+	}
+	return configured
+}
+
+// Cost returns the cost of the usage in USD
+func (u Usage) Cost(translator string) float64 {
+	p := PriceOf(translator)
+	units := float64(u.PromptTokens + u.Characters)
+	return (units*p.Input + float64(u.CompletionTokens)*p.Output) / 1e6
+}
+
+// promptOverhead estimates the tokens of the instructions and context added to
+// every LLM request
+const promptOverhead = 500
+
+// EstimateUsage estimates the usage of a request before it is sent, for
+// budget checks
+func EstimateUsage(translator string, req Request) Usage {
+	var u Usage
+	for k, v := range req.nonEmpty() {
+		if isLLM(translator) {
+			tokens := EstimateTokens(k) + EstimateTokens(v)
+			u.PromptTokens += tokens
+			u.CompletionTokens += tokens
+		} else {
+			u.Characters += utf8.RuneCountInString(v)
+		}
+	}
+	if isLLM(translator) {
+		u.PromptTokens += promptOverhead
+	}
+	return u
+}
+
+// isLLM reports whether a translator name belongs to an LLM provider
+func isLLM(translator string) bool {
+	return strings.HasPrefix(translator, ProviderOpenAI+"/") || strings.HasPrefix(translator, ProviderOllama+"/")
+}
+
+// TranslatorName returns the name a provider's translator is recorded under
+func TranslatorName(p models.MTProvider) string {
+	switch p.Provider {
+	case ProviderOpenAI, ProviderOllama:
+		model := p.Model
+		if model == "" {
+			model = defaultModel(p.Provider)
+		}
+		return p.Provider + "/" + model
+	}
+	return p.Provider
+}
+
+// MonthlyBudget returns the spending limit in USD per calendar month for all
+// projects together (AI_MONTHLY_BUDGET), 0 for none
+func MonthlyBudget() float64 {
+	if budget, err := strconv.ParseFloat(os.Getenv("AI_MONTHLY_BUDGET"), 64); err == nil && budget > 0 {
+		return budget
+	}
+	return 0
+}
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"templui/internal/models"
+)
+
+// FileUpdate is the new content of a file and the revisions describing the change
+type FileUpdate struct {
+	ID           string
+	Content      string
+	Revisions    []models.Revision
+	SourceHashes map[string]string // Source hashes to record for unchanged values
+	Statuses     map[string]string // Workflow statuses to set after the revisions
+}
+
+// BaseUpdate replaces a project's base file and migrates everything keyed by
+// translation key along with it
+type BaseUpdate struct {
+	ProjectID   string
+	Base        FileUpdate
+	Targets     []FileUpdate
+	Renames     map[string]string // Old key → new key
+	Removed     []string
+	ReviewFlags []models.ReviewFlag
+}
+
+// ApplyBaseUpdate writes the new base and target files, moves everything keyed
+// by translation key (metadata, screenshot regions, review flags, comments,
+// suggestions, workflow statuses, source hashes and quality assessments) from
+// renamed keys to their new name, drops it for removed keys and records the
+// new review flags, all in a single transaction
+func (db *DB) ApplyBaseUpdate(u BaseUpdate) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The revisions below clear the old keys and set the new ones as freshly
+	// translated, so the status and source hash of renamed keys are read first
+	// and carried over afterwards unless the update sets its own
+	carried := make(map[string][]keyState, len(u.Targets))
+	for _, f := range u.Targets {
+		states, err := renamedKeyStates(tx, f.ID, u.Renames)
+		if err != nil {
+			return err
+		}
+		carried[f.ID] = states
+	}
+
+	now := time.Now()
+	for _, f := range append([]FileUpdate{u.Base}, u.Targets...) {
+		if _, err := tx.Exec(`UPDATE files SET content = ?, updated_at = ? WHERE id = ?`, f.Content, now, f.ID); err != nil {
+			return err
+		}
+		if err := insertRevisions(tx, f.Revisions); err != nil {
+			return err
+		}
+		for _, s := range carried[f.ID] {
+			if _, ok := f.Statuses[s.key]; !ok && s.status != "" {
+				if err := setStatus(tx, f.ID, s.key, s.status, s.updatedBy, now); err != nil {
+					return err
+				}
+			}
+			if _, ok := f.SourceHashes[s.key]; !ok && s.sourceHash != "" {
+				if err := setSourceHashes(tx, f.ID, map[string]string{s.key: s.sourceHash}); err != nil {
+					return err
+				}
+			}
+		}
+		if err := setSourceHashes(tx, f.ID, f.SourceHashes); err != nil {
+			return err
+		}
+		for key, status := range f.Statuses {
+			if err := setStatus(tx, f.ID, key, status, "", now); err != nil {
+				return err
+			}
+		}
+	}
+
+	for from, to := range u.Renames {
+		for _, table := range []string{"key_metadata", "review_flags"} {
+			if _, err := tx.Exec(`UPDATE OR REPLACE `+table+` SET key = ? WHERE project_id = ? AND key = ?`, to, u.ProjectID, from); err != nil {
+				return err
+			}
+		}
+		for _, table := range []string{"screenshot_regions", "comment_threads", "suggestions"} {
+			if _, err := tx.Exec(`UPDATE `+table+` SET key = ? WHERE project_id = ? AND key = ?`, to, u.ProjectID, from); err != nil {
+				return err
+			}
+		}
+		// Assessments stay valid, they are tied to the value's hash
+		for _, f := range u.Targets {
+			if _, err := tx.Exec(`UPDATE OR REPLACE translation_quality SET key = ? WHERE file_id = ? AND key = ?`, to, f.ID, from); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, key := range u.Removed {
+		if _, err := tx.Exec(`DELETE FROM suggestion_votes WHERE suggestion_id IN (SELECT id FROM suggestions WHERE project_id = ? AND key = ?)`, u.ProjectID, key); err != nil {
+			return err
+		}
+		for _, table := range []string{"key_metadata", "review_flags", "screenshot_regions", "comment_threads", "suggestions"} {
+			if _, err := tx.Exec(`DELETE FROM `+table+` WHERE project_id = ? AND key = ?`, u.ProjectID, key); err != nil {
+				return err
+			}
+		}
+		for _, f := range u.Targets {
+			for _, table := range []string{"translation_statuses", "translation_sources", "translation_quality"} {
+				if _, err := tx.Exec(`DELETE FROM `+table+` WHERE file_id = ? AND key = ?`, f.ID, key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, f := range u.ReviewFlags {
+		query := `INSERT OR REPLACE INTO review_flags (project_id, language_code, key, reason, old_source, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+		if _, err := tx.Exec(query, f.ProjectID, f.LanguageCode, f.Key, f.Reason, f.OldSource, f.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// keyState is the stored workflow status and source hash of a key, under the
+// key's new name
+type keyState struct {
+	key        string
+	status     string
+	updatedBy  string
+	sourceHash string
+}
+
+// renamedKeyStates reads the status and source hash of the renamed keys of a file
+func renamedKeyStates(tx *sql.Tx, fileID string, renames map[string]string) ([]keyState, error) {
+	var states []keyState
+	for from, to := range renames {
+		s := keyState{key: to}
+		err := tx.QueryRow(`SELECT status, updated_by FROM translation_statuses WHERE file_id = ? AND key = ?`, fileID, from).Scan(&s.status, &s.updatedBy)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		err = tx.QueryRow(`SELECT source_hash FROM translation_sources WHERE file_id = ? AND key = ?`, fileID, from).Scan(&s.sourceHash)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		states = append(states, s)
+	}
+	return states, nil
+}
+
+// GetReviewFlags retrieves the review flags of a project's language, keyed by translation key
+func (db *DB) GetReviewFlags(projectID, languageCode string) (map[string]models.ReviewFlag, error) {
+	query := `SELECT project_id, language_code, key, reason, old_source, created_at FROM review_flags
+	          WHERE project_id = ? AND language_code = ?`
+	rows, err := db.conn.Query(query, projectID, languageCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := make(map[string]models.ReviewFlag)
+	for rows.Next() {
+		var f models.ReviewFlag
+		if err := rows.Scan(&f.ProjectID, &f.LanguageCode, &f.Key, &f.Reason, &f.OldSource, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		flags[f.Key] = f
+	}
+	return flags, rows.Err()
+}
+
+// ClearReviewFlags removes the review flags of the given keys
+func (db *DB) ClearReviewFlags(projectID, languageCode string, keys []string) error {
+	for _, key := range keys {
+		if _, err := db.conn.Exec(`DELETE FROM review_flags WHERE project_id = ? AND language_code = ? AND key = ?`, projectID, languageCode, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"templui/internal/models"
+)
+
+// CommentFilter narrows the comment threads returned by GetCommentThreads
+type CommentFilter struct {
+	Key          string // Only threads on this key
+	LanguageCode string // Only threads for this language or for all languages
+	Status       string // "open", "resolved" or empty for both
+	Mention      string // Only threads mentioning this collaborator name
+}
+
+// CreateCommentThread stores a new thread together with its first comment
+func (db *DB) CreateCommentThread(thread *models.CommentThread, comment *models.Comment) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO comment_threads (id, project_id, key, language_code, created_at) VALUES (?, ?, ?, ?, ?)`
+	if _, err := tx.Exec(query, thread.ID, thread.ProjectID, thread.Key, thread.LanguageCode, thread.CreatedAt); err != nil {
+		return err
+	}
+	if err := insertComment(tx, comment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddComment stores a reply in an existing thread
+func (db *DB) AddComment(comment *models.Comment) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertComment(tx, comment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertComment(tx *sql.Tx, c *models.Comment) error {
+	query := `INSERT INTO comments (id, thread_id, author_type, author_id, author_name, body, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	if _, err := tx.Exec(query, c.ID, c.ThreadID, c.AuthorType, c.AuthorID, c.AuthorName, c.Body, c.CreatedAt); err != nil {
+		return err
+	}
+	for _, name := range c.Mentions {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO comment_mentions (comment_id, name) VALUES (?, ?)`, c.ID, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCommentThread retrieves a thread with its comments
+func (db *DB) GetCommentThread(id string) (*models.CommentThread, error) {
+	query := `SELECT id, project_id, key, language_code, resolved, resolved_by, resolved_at, created_at
+	          FROM comment_threads WHERE id = ?`
+	var t models.CommentThread
+	if err := db.conn.QueryRow(query, id).Scan(&t.ID, &t.ProjectID, &t.Key, &t.LanguageCode, &t.Resolved, &t.ResolvedBy, &t.ResolvedAt, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	comments, err := db.getComments(t.ID)
+	if err != nil {
+		return nil, err
+	}
+	t.Comments = comments
+	return &t, nil
+}
+
+// GetCommentThreads retrieves the threads of a project, open ones first and
+// oldest first, with their comments
+func (db *DB) GetCommentThreads(projectID string, filter CommentFilter) ([]models.CommentThread, error) {
+	conditions := []string{"t.project_id = ?"}
+	args := []interface{}{projectID}
+	if filter.Key != "" {
+		conditions = append(conditions, "t.key = ?")
+		args = append(args, filter.Key)
+	}
+	if filter.LanguageCode != "" {
+		conditions = append(conditions, "(t.language_code = '' OR t.language_code = ?)")
+		args = append(args, filter.LanguageCode)
+	}
+	switch filter.Status {
+	case "open":
+		conditions = append(conditions, "t.resolved = FALSE")
+	case "resolved":
+		conditions = append(conditions, "t.resolved = TRUE")
+	}
+	if filter.Mention != "" {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM comments c JOIN comment_mentions m ON m.comment_id = c.id
+		                                         WHERE c.thread_id = t.id AND m.name = ? COLLATE NOCASE)`)
+		args = append(args, filter.Mention)
+	}
+
+	query := `SELECT t.id, t.project_id, t.key, t.language_code, t.resolved, t.resolved_by, t.resolved_at, t.created_at
+	          FROM comment_threads t WHERE ` + strings.Join(conditions, " AND ") + `
+	          ORDER BY t.resolved, t.created_at`
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	threads := []models.CommentThread{}
+	for rows.Next() {
+		var t models.CommentThread
+		if err := rows.Scan(&t.ID, &t.ProjectID, &t.Key, &t.LanguageCode, &t.Resolved, &t.ResolvedBy, &t.ResolvedAt, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		threads = append(threads, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range threads {
+		comments, err := db.getComments(threads[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		threads[i].Comments = comments
+	}
+	return threads, nil
+}
+
+// getComments lists the comments of a thread in posting order
+func (db *DB) getComments(threadID string) ([]models.Comment, error) {
+	query := `SELECT c.id, c.thread_id, c.author_type, c.author_id, c.author_name, c.body, c.created_at,
+	                 COALESCE((SELECT GROUP_CONCAT(m.name, ' ') FROM comment_mentions m WHERE m.comment_id = c.id), '')
+	          FROM comments c WHERE c.thread_id = ? ORDER BY c.created_at`
+	rows, err := db.conn.Query(query, threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		var c models.Comment
+		var mentions string
+		if err := rows.Scan(&c.ID, &c.ThreadID, &c.AuthorType, &c.AuthorID, &c.AuthorName, &c.Body, &c.CreatedAt, &mentions); err != nil {
+			return nil, err
+		}
+		c.Mentions = strings.Fields(mentions)
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// SetCommentThreadResolved resolves or reopens a thread
+func (db *DB) SetCommentThreadResolved(id string, resolved bool, resolvedBy string) error {
+	var resolvedAt *time.Time
+	if resolved {
+		now := time.Now()
+		resolvedAt = &now
+	} else {
+		resolvedBy = ""
+	}
+	_, err := db.conn.Exec(`UPDATE comment_threads SET resolved = ?, resolved_by = ?, resolved_at = ? WHERE id = ?`, resolved, resolvedBy, resolvedAt, id)
+	return err
+}
+
+// CountOpenCommentThreads counts the unresolved threads of a project by key,
+// including threads for all languages and for languageCode
+func (db *DB) CountOpenCommentThreads(projectID, languageCode string) (map[string]int, error) {
+	query := `SELECT key, COUNT(*) FROM comment_threads
+	          WHERE project_id = ? AND resolved = FALSE AND (language_code = '' OR language_code = ?)
+	          GROUP BY key`
+	rows, err := db.conn.Query(query, projectID, languageCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var key string
+		var n int
+		if err := rows.Scan(&key, &n); err != nil {
+			return nil, err
+		}
+		counts[key] = n
+	}
+	return counts, rows.Err()
+}
+
+// SetCollaboratorName sets the display name of a session or API key in a
+// project. Names are unique per project.
+func (db *DB) SetCollaboratorName(projectID, authorID, name string) error {
+	query := `INSERT INTO collaborators (project_id, author_id, name, created_at) VALUES (?, ?, ?, ?)
+	          ON CONFLICT (project_id, author_id) DO UPDATE SET name = excluded.name`
+	_, err := db.conn.Exec(query, projectID, authorID, name, time.Now())
+	return err
+}
+
+// GetCollaborator retrieves the collaborator registered for an author
+func (db *DB) GetCollaborator(projectID, authorID string) (*models.Collaborator, error) {
+	query := `SELECT project_id, author_id, name, created_at FROM collaborators WHERE project_id = ? AND author_id = ?`
+	var c models.Collaborator
+	if err := db.conn.QueryRow(query, projectID, authorID).Scan(&c.ProjectID, &c.AuthorID, &c.Name, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetCollaborators lists the named collaborators of a project by name
+func (db *DB) GetCollaborators(projectID string) ([]models.Collaborator, error) {
+	rows, err := db.conn.Query(`SELECT project_id, author_id, name, created_at FROM collaborators WHERE project_id = ? ORDER BY name`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collaborators := []models.Collaborator{}
+	for rows.Next() {
+		var c models.Collaborator
+		if err := rows.Scan(&c.ProjectID, &c.AuthorID, &c.Name, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		collaborators = append(collaborators, c)
+	}
+	return collaborators, rows.Err()
+}
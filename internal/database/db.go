@@ -30,6 +30,14 @@ func NewDB() (*DB, error) {
 		// Local SQLite file
 		driver = "sqlite"
 		dsn = strings.TrimPrefix(dbURL, "file:")
+		// Background jobs write while requests read, wait for locks instead of failing
+		if !strings.Contains(dsn, "busy_timeout") {
+			sep := "?"
+			if strings.Contains(dsn, "?") {
+				sep = "&"
+			}
+			dsn += sep + "_pragma=busy_timeout(5000)"
+		}
 	} else if strings.HasPrefix(dbURL, "libsql://") {
 		// TursoDB / libSQL
 		driver = "libsql"
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"templui/internal/models"
+)
+
+// CreateGlossary stores a new glossary without terms
+func (db *DB) CreateGlossary(g *models.Glossary) error {
+	var projectID sql.NullString
+	if g.ProjectID != "" {
+		projectID = sql.NullString{String: g.ProjectID, Valid: true}
+	}
+	query := `INSERT INTO glossaries (id, name, project_id, session_token, created_at) VALUES (?, ?, ?, ?, ?)`
+	_, err := db.conn.Exec(query, g.ID, g.Name, projectID, g.SessionToken, g.CreatedAt)
+	return err
+}
+
+// GetGlossary retrieves a glossary with its terms
+func (db *DB) GetGlossary(id string) (*models.Glossary, error) {
+	return db.getGlossary(`WHERE id = ?`, id)
+}
+
+// GetProjectGlossary retrieves the glossary owned by a project with its terms
+func (db *DB) GetProjectGlossary(projectID string) (*models.Glossary, error) {
+	return db.getGlossary(`WHERE project_id = ?`, projectID)
+}
+
+func (db *DB) getGlossary(where string, arg string) (*models.Glossary, error) {
+	query := `SELECT id, name, COALESCE(project_id, ''), session_token, created_at FROM glossaries ` + where
+	var g models.Glossary
+	if err := db.conn.QueryRow(query, arg).Scan(&g.ID, &g.Name, &g.ProjectID, &g.SessionToken, &g.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	terms, err := db.getGlossaryTerms(g.ID)
+	if err != nil {
+		return nil, err
+	}
+	g.Terms = terms
+	return &g, nil
+}
+
+// GetLinkedGlossaries retrieves the organization glossaries linked to a project with their terms
+func (db *DB) GetLinkedGlossaries(projectID string) ([]models.Glossary, error) {
+	query := `SELECT g.id, g.name, g.session_token, g.created_at
+	          FROM glossaries g JOIN project_glossaries pg ON pg.glossary_id = g.id
+	          WHERE pg.project_id = ? ORDER BY g.name`
+	rows, err := db.conn.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	glossaries := []models.Glossary{}
+	for rows.Next() {
+		var g models.Glossary
+		if err := rows.Scan(&g.ID, &g.Name, &g.SessionToken, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		glossaries = append(glossaries, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range glossaries {
+		terms, err := db.getGlossaryTerms(glossaries[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		glossaries[i].Terms = terms
+	}
+	return glossaries, nil
+}
+
+// LinkGlossary makes an organization glossary apply to a project
+func (db *DB) LinkGlossary(projectID, glossaryID string) error {
+	_, err := db.conn.Exec(`INSERT OR IGNORE INTO project_glossaries (project_id, glossary_id) VALUES (?, ?)`, projectID, glossaryID)
+	return err
+}
+
+// UnlinkGlossary removes an organization glossary from a project
+func (db *DB) UnlinkGlossary(projectID, glossaryID string) error {
+	_, err := db.conn.Exec(`DELETE FROM project_glossaries WHERE project_id = ? AND glossary_id = ?`, projectID, glossaryID)
+	return err
+}
+
+// getGlossaryTerms lists the terms of a glossary alphabetically with their translations
+func (db *DB) getGlossaryTerms(glossaryID string) ([]models.GlossaryTerm, error) {
+	query := `SELECT id, glossary_id, term, case_sensitive, do_not_translate, note, created_at
+	          FROM glossary_terms WHERE glossary_id = ? ORDER BY term COLLATE NOCASE`
+	rows, err := db.conn.Query(query, glossaryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terms := []models.GlossaryTerm{}
+	index := make(map[string]int)
+	for rows.Next() {
+		t := models.GlossaryTerm{Translations: make(map[string]models.TermTranslation)}
+		if err := rows.Scan(&t.ID, &t.GlossaryID, &t.Term, &t.CaseSensitive, &t.DoNotTranslate, &t.Note, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		index[t.ID] = len(terms)
+		terms = append(terms, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `SELECT tr.term_id, tr.language_code, tr.translation, tr.forbidden
+	         FROM glossary_translations tr JOIN glossary_terms t ON t.id = tr.term_id
+	         WHERE t.glossary_id = ?`
+	trRows, err := db.conn.Query(query, glossaryID)
+	if err != nil {
+		return nil, err
+	}
+	defer trRows.Close()
+
+	for trRows.Next() {
+		var termID, lang, forbidden string
+		var tr models.TermTranslation
+		if err := trRows.Scan(&termID, &lang, &tr.Translation, &forbidden); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(forbidden), &tr.Forbidden)
+		if i, ok := index[termID]; ok {
+			terms[i].Translations[lang] = tr
+		}
+	}
+	return terms, trRows.Err()
+}
+
+// GetGlossaryTerm retrieves a single term with its translations
+func (db *DB) GetGlossaryTerm(id string) (*models.GlossaryTerm, error) {
+	var glossaryID string
+	if err := db.conn.QueryRow(`SELECT glossary_id FROM glossary_terms WHERE id = ?`, id).Scan(&glossaryID); err != nil {
+		return nil, err
+	}
+	terms, err := db.getGlossaryTerms(glossaryID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range terms {
+		if terms[i].ID == id {
+			return &terms[i], nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// SaveGlossaryTerm creates or replaces a term and all of its translations
+func (db *DB) SaveGlossaryTerm(t *models.GlossaryTerm) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO glossary_terms (id, glossary_id, term, case_sensitive, do_not_translate, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			term = excluded.term,
+			case_sensitive = excluded.case_sensitive,
+			do_not_translate = excluded.do_not_translate,
+			note = excluded.note
+	`
+	if _, err := tx.Exec(query, t.ID, t.GlossaryID, t.Term, t.CaseSensitive, t.DoNotTranslate, t.Note, t.CreatedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM glossary_translations WHERE term_id = ?`, t.ID); err != nil {
+		return err
+	}
+	for lang, tr := range t.Translations {
+		forbidden, err := json.Marshal(tr.Forbidden)
+		if err != nil {
+			return err
+		}
+		query := `INSERT INTO glossary_translations (term_id, language_code, translation, forbidden) VALUES (?, ?, ?, ?)`
+		if _, err := tx.Exec(query, t.ID, lang, tr.Translation, string(forbidden)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteGlossaryTerm removes a term and its translations
+func (db *DB) DeleteGlossaryTerm(id string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM glossary_translations WHERE term_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM glossary_terms WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
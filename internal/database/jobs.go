@@ -0,0 +1,219 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"templui/internal/models"
+)
+
+const jobColumns = `id, type, project_id, owner, status, params, result, error, progress_done, progress_total, attempts, cancel_requested, created_at, started_at, finished_at`
+
+func scanJob(row interface{ Scan(...interface{}) error }) (*models.Job, error) {
+	var j models.Job
+	var params, result string
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(&j.ID, &j.Type, &j.ProjectID, &j.Owner, &j.Status, &params, &result, &j.Error,
+		&j.Done, &j.Total, &j.Attempts, &j.CancelRequested, &j.CreatedAt, &startedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	j.Params = []byte(params)
+	if result != "" {
+		j.Result = []byte(result)
+	}
+	if startedAt.Valid {
+		j.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		j.FinishedAt = &finishedAt.Time
+	}
+	return &j, nil
+}
+
+// CreateJob queues a new job
+func (db *DB) CreateJob(job *models.Job) error {
+	params := string(job.Params)
+	if params == "" {
+		params = "{}"
+	}
+	_, err := db.conn.Exec(`
+		INSERT INTO jobs (id, type, project_id, owner, status, params, progress_total, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, job.ID, job.Type, job.ProjectID, job.Owner, job.Status, params, job.Total, job.CreatedAt, job.CreatedAt)
+	return err
+}
+
+// CreateJobUnlessActive queues a new job unless the project already has a
+// queued or running job of the same type. It returns the ID of that job, or ""
+// when the new job was created.
+func (db *DB) CreateJobUnlessActive(job *models.Job) (string, error) {
+	params := string(job.Params)
+	if params == "" {
+		params = "{}"
+	}
+	res, err := db.conn.Exec(`
+		INSERT INTO jobs (id, type, project_id, owner, status, params, progress_total, created_at, updated_at)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM jobs WHERE project_id = ? AND type = ? AND status IN (?, ?))
+	`, job.ID, job.Type, job.ProjectID, job.Owner, job.Status, params, job.Total, job.CreatedAt, job.CreatedAt,
+		job.ProjectID, job.Type, models.JobQueued, models.JobRunning)
+	if err != nil {
+		return "", err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return "", nil
+	}
+	var id string
+	err = db.conn.QueryRow(`
+		SELECT id FROM jobs WHERE project_id = ? AND type = ? AND status IN (?, ?) ORDER BY created_at LIMIT 1
+	`, job.ProjectID, job.Type, models.JobQueued, models.JobRunning).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The other job finished in the meantime
+		return db.CreateJobUnlessActive(job)
+	}
+	return id, err
+}
+
+// RemoveJobParams deletes parameters from finished jobs of a type
+func (db *DB) RemoveJobParams(jobType string, fields []string) error {
+	for _, field := range fields {
+		path := "$." + field
+		if _, err := db.conn.Exec(`
+			UPDATE jobs SET params = json_remove(params, ?)
+			WHERE type = ? AND status IN (?, ?, ?) AND json_type(params, ?) IS NOT NULL
+		`, path, jobType, models.JobSucceeded, models.JobFailed, models.JobCancelled, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetJob retrieves a job by ID
+func (db *DB) GetJob(id string) (*models.Job, error) {
+	return scanJob(db.conn.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id))
+}
+
+// GetProjectJobs lists the most recent jobs of a project, optionally only
+// those with one of the given statuses
+func (db *DB) GetProjectJobs(projectID string, statuses []string, limit int) ([]models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE project_id = ?`
+	args := []interface{}{projectID}
+	if len(statuses) > 0 {
+		query += ` AND status IN (` + placeholders(len(statuses)) + `)`
+		for _, s := range statuses {
+			args = append(args, s)
+		}
+	}
+	query += ` ORDER BY created_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, rows.Err()
+}
+
+// ClaimJob marks the oldest queued job as running and returns it, or nil when
+// the queue is empty
+func (db *DB) ClaimJob() (*models.Job, error) {
+	for {
+		var id string
+		err := db.conn.QueryRow(`SELECT id FROM jobs WHERE status = ? ORDER BY created_at LIMIT 1`, models.JobQueued).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Another worker may have claimed it in the meantime
+		now := time.Now()
+		res, err := db.conn.Exec(`
+			UPDATE jobs SET status = ?, attempts = attempts + 1, started_at = ?, updated_at = ?
+			WHERE id = ? AND status = ?
+		`, models.JobRunning, now, now, id, models.JobQueued)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			return db.GetJob(id)
+		}
+	}
+}
+
+// UpdateJobProgress records how much of a running job is done
+func (db *DB) UpdateJobProgress(id string, done, total int) error {
+	_, err := db.conn.Exec(`UPDATE jobs SET progress_done = ?, progress_total = ?, updated_at = ? WHERE id = ?`, done, total, time.Now(), id)
+	return err
+}
+
+// FinishJob stores the final status of a job with its result or error
+func (db *DB) FinishJob(id, status string, result []byte, errorMessage string) error {
+	now := time.Now()
+	_, err := db.conn.Exec(`
+		UPDATE jobs SET status = ?, result = ?, error = ?, finished_at = ?, updated_at = ? WHERE id = ?
+	`, status, string(result), errorMessage, now, now, id)
+	return err
+}
+
+// CancelJob cancels a queued job right away and asks the worker of a running
+// job to stop. It reports false when the job has already finished.
+func (db *DB) CancelJob(id string) (bool, error) {
+	now := time.Now()
+	res, err := db.conn.Exec(`
+		UPDATE jobs SET status = ?, cancel_requested = 1, finished_at = ?, updated_at = ? WHERE id = ? AND status = ?
+	`, models.JobCancelled, now, now, id, models.JobQueued)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return true, nil
+	}
+	res, err = db.conn.Exec(`UPDATE jobs SET cancel_requested = 1, updated_at = ? WHERE id = ? AND status = ?`, now, id, models.JobRunning)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
+
+// IsJobCancelRequested reports whether someone asked to cancel a job
+func (db *DB) IsJobCancelRequested(id string) (bool, error) {
+	var cancel bool
+	err := db.conn.QueryRow(`SELECT cancel_requested FROM jobs WHERE id = ?`, id).Scan(&cancel)
+	return cancel, err
+}
+
+// RequeueInterruptedJobs puts jobs that were running when the server stopped
+// back into the queue. Jobs that were already attempted maxAttempts times fail.
+func (db *DB) RequeueInterruptedJobs(maxAttempts int) (int, error) {
+	now := time.Now()
+	if _, err := db.conn.Exec(`
+		UPDATE jobs SET status = ?, error = 'interrupted too often', finished_at = ?, updated_at = ?
+		WHERE status = ? AND attempts >= ?
+	`, models.JobFailed, now, now, models.JobRunning, maxAttempts); err != nil {
+		return 0, err
+	}
+	res, err := db.conn.Exec(`
+		UPDATE jobs SET status = CASE WHEN cancel_requested THEN ? ELSE ? END, updated_at = ?
+		WHERE status = ?
+	`, models.JobCancelled, models.JobQueued, now, models.JobRunning)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
@@ -0,0 +1,221 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"unicode/utf8"
+
+	"templui/internal/jsontools"
+	"templui/internal/models"
+)
+
+const memoryColumns = `id, owner, source_lang, target_lang, source_text, target_text, project_id, origin, use_count, created_at, updated_at`
+
+// AddMemoryEntries stores source/target pairs in the translation memory.
+// Pairs already stored for the owner are refreshed and their use count increased.
+func (db *DB) AddMemoryEntries(entries []models.MemoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO translation_memory (` + memoryColumns + `, source_length)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (owner, source_lang, target_lang, source_text, target_text) DO UPDATE SET
+			use_count = use_count + excluded.use_count,
+			project_id = excluded.project_id,
+			origin = excluded.origin,
+			updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		if e.UseCount == 0 {
+			e.UseCount = 1
+		}
+		length := utf8.RuneCountInString(e.SourceText)
+		if _, err := stmt.Exec(e.ID, e.Owner, e.SourceLang, e.TargetLang, e.SourceText, e.TargetText, e.ProjectID, e.Origin, e.UseCount, e.CreatedAt, e.UpdatedAt, length); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetMemoryEntry retrieves a translation memory entry of one of the owners
+func (db *DB) GetMemoryEntry(owners []string, id string) (*models.MemoryEntry, error) {
+	args := append(ownerArgs(owners), id)
+	row := db.conn.QueryRow(`SELECT `+memoryColumns+` FROM translation_memory WHERE owner IN (`+placeholders(len(owners))+`) AND id = ?`, args...)
+	var e models.MemoryEntry
+	if err := row.Scan(&e.ID, &e.Owner, &e.SourceLang, &e.TargetLang, &e.SourceText, &e.TargetText, &e.ProjectID, &e.Origin, &e.UseCount, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// FindExactMemory returns the best stored translation for each source text in
+// the memories of the owners. When a source was translated differently over
+// time, the most used and then most recent translation wins.
+func (db *DB) FindExactMemory(owners []string, sourceLang, targetLang string, texts []string) (map[string]models.MemoryEntry, error) {
+	query := `SELECT ` + memoryColumns + ` FROM translation_memory
+	          WHERE owner IN (` + placeholders(len(owners)) + `) AND source_lang = ? AND target_lang = ? AND source_text = ?
+	          ORDER BY use_count DESC, updated_at DESC LIMIT 1`
+	stmt, err := db.conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	result := make(map[string]models.MemoryEntry)
+	for _, text := range texts {
+		var e models.MemoryEntry
+		args := append(ownerArgs(owners), sourceLang, targetLang, text)
+		err := stmt.QueryRow(args...).Scan(&e.ID, &e.Owner, &e.SourceLang, &e.TargetLang, &e.SourceText, &e.TargetText, &e.ProjectID, &e.Origin, &e.UseCount, &e.CreatedAt, &e.UpdatedAt)
+		if err == nil {
+			result[text] = e
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// FindMemoryMatches returns the entries in the memories of the owners whose
+// source text is at least minScore percent similar to text by edit distance,
+// best matches first
+func (db *DB) FindMemoryMatches(owners []string, sourceLang, targetLang, text string, minScore, limit int) ([]models.MemoryMatch, error) {
+	// Texts whose length differs by more than the allowed distance cannot match
+	length := utf8.RuneCountInString(text)
+	minLength := length * minScore / 100
+	maxLength := length * 100 / max(minScore, 1)
+
+	query := `SELECT ` + memoryColumns + ` FROM translation_memory
+	          WHERE owner IN (` + placeholders(len(owners)) + `) AND source_lang = ? AND target_lang = ? AND source_length BETWEEN ? AND ?
+	          ORDER BY ABS(source_length - ?), use_count DESC LIMIT 1000`
+	rows, err := db.conn.Query(query, append(ownerArgs(owners), sourceLang, targetLang, minLength, maxLength, length)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []models.MemoryMatch{}
+	for rows.Next() {
+		var m models.MemoryMatch
+		if err := rows.Scan(&m.ID, &m.Owner, &m.SourceLang, &m.TargetLang, &m.SourceText, &m.TargetText, &m.ProjectID, &m.Origin, &m.UseCount, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if m.SourceText == text {
+			m.Score = 100
+		} else {
+			// Near-identical texts that differ only in case or spacing still score below 100
+			m.Score = min(int(jsontools.Similarity(m.SourceText, text)*100), 99)
+		}
+		if m.Score >= minScore {
+			matches = append(matches, m)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].UseCount > matches[j].UseCount
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// ListMemoryEntries lists the translation memory of an owner, optionally
+// limited to a source and target language, grouped by source text
+func (db *DB) ListMemoryEntries(owner, sourceLang, targetLang string) ([]models.MemoryEntry, error) {
+	query := `SELECT ` + memoryColumns + ` FROM translation_memory
+	          WHERE owner = ? AND (? = '' OR source_lang = ?) AND (? = '' OR target_lang = ?)
+	          ORDER BY source_lang, source_text, target_lang, use_count DESC`
+	rows, err := db.conn.Query(query, owner, sourceLang, sourceLang, targetLang, targetLang)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.MemoryEntry{}
+	for rows.Next() {
+		var e models.MemoryEntry
+		if err := rows.Scan(&e.ID, &e.Owner, &e.SourceLang, &e.TargetLang, &e.SourceText, &e.TargetText, &e.ProjectID, &e.Origin, &e.UseCount, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ownerArgs returns the owners as query arguments
+func ownerArgs(owners []string) []interface{} {
+	args := make([]interface{}, len(owners))
+	for i, owner := range owners {
+		args[i] = owner
+	}
+	return args
+}
+
+// CreateMemory creates an organization translation memory
+func (db *DB) CreateMemory(m *models.Memory) error {
+	_, err := db.conn.Exec(`INSERT INTO memories (id, name, session_token, created_at) VALUES (?, ?, ?, ?)`, m.ID, m.Name, m.SessionToken, m.CreatedAt)
+	return err
+}
+
+// GetMemory retrieves an organization translation memory
+func (db *DB) GetMemory(id string) (*models.Memory, error) {
+	var m models.Memory
+	err := db.conn.QueryRow(`SELECT id, name, session_token, created_at FROM memories WHERE id = ?`, id).Scan(&m.ID, &m.Name, &m.SessionToken, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetLinkedMemories retrieves the organization memories linked to a project
+func (db *DB) GetLinkedMemories(projectID string) ([]models.Memory, error) {
+	query := `SELECT m.id, m.name, m.session_token, m.created_at
+	          FROM memories m JOIN project_memories pm ON pm.memory_id = m.id
+	          WHERE pm.project_id = ? ORDER BY m.name`
+	rows, err := db.conn.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memories := []models.Memory{}
+	for rows.Next() {
+		var m models.Memory
+		if err := rows.Scan(&m.ID, &m.Name, &m.SessionToken, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// LinkMemory makes an organization memory apply to a project
+func (db *DB) LinkMemory(projectID, memoryID string) error {
+	_, err := db.conn.Exec(`INSERT OR IGNORE INTO project_memories (project_id, memory_id) VALUES (?, ?)`, projectID, memoryID)
+	return err
+}
+
+// UnlinkMemory removes an organization memory from a project
+func (db *DB) UnlinkMemory(projectID, memoryID string) error {
+	_, err := db.conn.Exec(`DELETE FROM project_memories WHERE project_id = ? AND memory_id = ?`, projectID, memoryID)
+	return err
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"time"
+
+	"templui/internal/models"
+)
+
+// GetCachedTranslations returns the unexpired cached translations of the
+// given cache keys by key, and counts the hits
+func (db *DB) GetCachedTranslations(keys []string, now time.Time) (map[string]string, error) {
+	translations := make(map[string]string)
+	if len(keys) == 0 {
+		return translations, nil
+	}
+	args := []interface{}{now.UTC()}
+	for _, k := range keys {
+		args = append(args, k)
+	}
+	rows, err := db.conn.Query(`
+		SELECT cache_key, translation FROM mt_cache WHERE expires_at > ? AND cache_key IN (`+placeholders(len(keys))+`)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, translation string
+		if err := rows.Scan(&key, &translation); err != nil {
+			return nil, err
+		}
+		translations[key] = translation
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if len(translations) > 0 {
+		hitArgs := make([]interface{}, 0, len(translations))
+		for k := range translations {
+			hitArgs = append(hitArgs, k)
+		}
+		if _, err := db.conn.Exec(`UPDATE mt_cache SET hits = hits + 1 WHERE cache_key IN (`+placeholders(len(hitArgs))+`)`, hitArgs...); err != nil {
+			return nil, err
+		}
+	}
+	return translations, nil
+}
+
+// CacheTranslations stores machine translations, replacing earlier entries
+// with the same cache key
+func (db *DB) CacheTranslations(entries []models.MTCacheEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO mt_cache (cache_key, provider, model, source_lang, target_lang, source_text, context_hash, translation, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (cache_key) DO UPDATE SET translation = excluded.translation, created_at = excluded.created_at, expires_at = excluded.expires_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		if _, err := stmt.Exec(e.Key, e.Provider, e.Model, e.SourceLang, e.TargetLang, e.SourceText, e.ContextHash, e.Translation,
+			e.CreatedAt.UTC(), e.ExpiresAt.UTC()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// PurgeMTCache deletes expired cache entries
+func (db *DB) PurgeMTCache(now time.Time) (int, error) {
+	res, err := db.conn.Exec(`DELETE FROM mt_cache WHERE expires_at <= ?`, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
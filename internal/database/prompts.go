@@ -0,0 +1,41 @@
+package database
+
+import (
+	"templui/internal/models"
+)
+
+// CreatePrompts records prompts sent to LLM providers
+func (db *DB) CreatePrompts(prompts []models.MTPrompt) error {
+	if len(prompts) == 0 {
+		return nil
+	}
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO mt_prompts (id, project_id, batch_id, translator, prompt, created_at) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, p := range prompts {
+		if _, err := stmt.Exec(p.ID, p.ProjectID, p.BatchID, p.Translator, p.Prompt, p.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetPrompt retrieves a recorded prompt of a project
+func (db *DB) GetPrompt(projectID, id string) (*models.MTPrompt, error) {
+	var p models.MTPrompt
+	err := db.conn.QueryRow(`SELECT id, project_id, batch_id, translator, prompt, created_at FROM mt_prompts WHERE id = ? AND project_id = ?`, id, projectID).
+		Scan(&p.ID, &p.ProjectID, &p.BatchID, &p.Translator, &p.Prompt, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"templui/internal/models"
+)
+
+// GetQualityAssessments returns the quality assessments of a target file by key
+func (db *DB) GetQualityAssessments(fileID string) (map[string]models.QualityAssessment, error) {
+	rows, err := db.conn.Query(`
+		SELECT file_id, key, target_hash, score, issues, back_translation, translator, created_at
+		FROM translation_quality WHERE file_id = ?
+	`, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assessments := make(map[string]models.QualityAssessment)
+	for rows.Next() {
+		var a models.QualityAssessment
+		var score sql.NullInt64
+		var issues string
+		if err := rows.Scan(&a.FileID, &a.Key, &a.TargetHash, &score, &issues, &a.BackTranslation, &a.Translator, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		if score.Valid {
+			s := int(score.Int64)
+			a.Score = &s
+		}
+		if err := json.Unmarshal([]byte(issues), &a.Issues); err != nil || a.Issues == nil {
+			a.Issues = []models.QualityIssue{}
+		}
+		assessments[a.Key] = a
+	}
+	return assessments, rows.Err()
+}
+
+// SaveQualityAssessments stores quality assessments. An earlier assessment of
+// the same value is merged: its score and issues are kept when the new one has
+// no score, and its back-translation when the new one has none. Assessments of
+// a previous value are replaced.
+func (db *DB) SaveQualityAssessments(assessments []models.QualityAssessment) error {
+	if len(assessments) == 0 {
+		return nil
+	}
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO translation_quality (file_id, key, target_hash, score, issues, back_translation, translator, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (file_id, key) DO UPDATE SET
+			score = CASE WHEN target_hash = excluded.target_hash THEN COALESCE(excluded.score, score) ELSE excluded.score END,
+			issues = CASE WHEN target_hash = excluded.target_hash AND excluded.score IS NULL THEN issues ELSE excluded.issues END,
+			back_translation = CASE WHEN target_hash = excluded.target_hash AND excluded.back_translation = ''
+				THEN back_translation ELSE excluded.back_translation END,
+			target_hash = excluded.target_hash, translator = excluded.translator, created_at = excluded.created_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, a := range assessments {
+		issues, err := json.Marshal(a.Issues)
+		if err != nil {
+			return err
+		}
+		var score sql.NullInt64
+		if a.Score != nil {
+			score = sql.NullInt64{Int64: int64(*a.Score), Valid: true}
+		}
+		if _, err := stmt.Exec(a.FileID, a.Key, a.TargetHash, score, string(issues), a.BackTranslation, a.Translator, a.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
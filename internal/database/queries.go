@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
@@ -21,14 +22,17 @@ func (db *DB) CreateProject(project *models.Project) error {
 
 // GetProject retrieves a project by ID
 func (db *DB) GetProject(id string) (*models.Project, error) {
-	query := `SELECT id, name, is_locked, secret_key_hash, secret_key, session_token, created_at, updated_at FROM projects WHERE id = ?`
+	query := `SELECT id, name, is_locked, secret_key_hash, secret_key, session_token, qa_rules, mt_settings, style_guide, created_at, updated_at FROM projects WHERE id = ?`
 	row := db.conn.QueryRow(query, id)
 
 	var project models.Project
 	var secretKeyHash sql.NullString
 	var secretKey sql.NullString
 	var sessionToken sql.NullString
-	err := row.Scan(&project.ID, &project.Name, &project.IsLocked, &secretKeyHash, &secretKey, &sessionToken, &project.CreatedAt, &project.UpdatedAt)
+	var qaRules sql.NullString
+	var mtSettings sql.NullString
+	var styleGuide sql.NullString
+	err := row.Scan(&project.ID, &project.Name, &project.IsLocked, &secretKeyHash, &secretKey, &sessionToken, &qaRules, &mtSettings, &styleGuide, &project.CreatedAt, &project.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -42,10 +46,52 @@ func (db *DB) GetProject(id string) (*models.Project, error) {
 	if sessionToken.Valid {
 		project.SessionToken = sessionToken.String
 	}
+	if qaRules.Valid && qaRules.String != "" {
+		json.Unmarshal([]byte(qaRules.String), &project.QARules)
+	}
+	if mtSettings.Valid && mtSettings.String != "" {
+		json.Unmarshal([]byte(mtSettings.String), &project.MTSettings)
+	}
+	if styleGuide.Valid && styleGuide.String != "" {
+		json.Unmarshal([]byte(styleGuide.String), &project.StyleGuide)
+	}
 
 	return &project, nil
 }
 
+// UpdateProjectQARules replaces a project's QA rule configuration
+func (db *DB) UpdateProjectQARules(id string, rules map[string]string) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE projects SET qa_rules = ?, updated_at = ? WHERE id = ?`
+	_, err = db.conn.Exec(query, string(data), time.Now(), id)
+	return err
+}
+
+// UpdateProjectMTSettings replaces a project's machine translation settings
+func (db *DB) UpdateProjectMTSettings(id string, settings models.MTSettings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE projects SET mt_settings = ?, updated_at = ? WHERE id = ?`
+	_, err = db.conn.Exec(query, string(data), time.Now(), id)
+	return err
+}
+
+// UpdateProjectStyleGuide replaces a project's style guide
+func (db *DB) UpdateProjectStyleGuide(id string, guide models.StyleGuide) error {
+	data, err := json.Marshal(guide)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE projects SET style_guide = ?, updated_at = ? WHERE id = ?`
+	_, err = db.conn.Exec(query, string(data), time.Now(), id)
+	return err
+}
+
 // ListProjects retrieves all projects
 func (db *DB) ListProjects(limit int) ([]models.Project, error) {
 	query := `SELECT id, name, is_locked, secret_key_hash, session_token, created_at, updated_at FROM projects ORDER BY created_at DESC LIMIT ?`
@@ -244,3 +290,64 @@ func (db *DB) UpdateTemplateLastUsed(id string) error {
 	_, err := db.conn.Exec(query, time.Now(), id)
 	return err
 }
+
+// UpsertKeyMetadata creates or replaces the metadata for a key
+func (db *DB) UpsertKeyMetadata(meta *models.KeyMetadata) error {
+	query := `
+		INSERT INTO key_metadata (project_id, key, description, context, max_length, tags, screenshot, do_not_translate, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (project_id, key) DO UPDATE SET
+			description = excluded.description,
+			context = excluded.context,
+			max_length = excluded.max_length,
+			tags = excluded.tags,
+			screenshot = excluded.screenshot,
+			do_not_translate = excluded.do_not_translate,
+			updated_at = excluded.updated_at
+	`
+	tags, err := json.Marshal(meta.Tags)
+	if err != nil {
+		return err
+	}
+	if meta.Tags == nil {
+		tags = []byte("[]")
+	}
+	_, err = db.conn.Exec(query, meta.ProjectID, meta.Key, meta.Description, meta.Context, meta.MaxLength, string(tags), meta.Screenshot, meta.DoNotTranslate, meta.UpdatedAt)
+	return err
+}
+
+// DeleteKeyMetadata removes the metadata for a key
+func (db *DB) DeleteKeyMetadata(projectID, key string) error {
+	query := `DELETE FROM key_metadata WHERE project_id = ? AND key = ?`
+	_, err := db.conn.Exec(query, projectID, key)
+	return err
+}
+
+// GetKeyMetadataByProject retrieves all key metadata for a project keyed by translation key
+func (db *DB) GetKeyMetadataByProject(projectID string) (map[string]models.KeyMetadata, error) {
+	query := `SELECT project_id, key, description, context, max_length, tags, screenshot, do_not_translate, updated_at
+	          FROM key_metadata WHERE project_id = ?`
+	rows, err := db.conn.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]models.KeyMetadata)
+	for rows.Next() {
+		var meta models.KeyMetadata
+		var tags string
+		if err := rows.Scan(&meta.ProjectID, &meta.Key, &meta.Description, &meta.Context, &meta.MaxLength, &tags, &meta.Screenshot, &meta.DoNotTranslate, &meta.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(tags), &meta.Tags); err != nil {
+			return nil, fmt.Errorf("invalid tags of key %s: %w", meta.Key, err)
+		}
+		if len(meta.Tags) == 0 {
+			meta.Tags = nil
+		}
+		result[meta.Key] = meta
+	}
+
+	return result, nil
+}
@@ -0,0 +1,255 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"templui/internal/models"
+)
+
+// UpdateFileWithRevisions updates a file's content and records the revisions
+// that describe the change in a single transaction
+func (db *DB) UpdateFileWithRevisions(id, content string, revisions []models.Revision) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE files SET content = ?, updated_at = ? WHERE id = ?`, content, time.Now(), id); err != nil {
+		return err
+	}
+
+	if err := insertRevisions(tx, revisions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateFiles writes several files and their revisions in a single
+// transaction, so either all of them change or none
+func (db *DB) UpdateFiles(updates []FileUpdate) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, f := range updates {
+		if _, err := tx.Exec(`UPDATE files SET content = ?, updated_at = ? WHERE id = ?`, f.Content, now, f.ID); err != nil {
+			return err
+		}
+		if err := insertRevisions(tx, f.Revisions); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CreateRevisions records revisions without touching file content
+func (db *DB) CreateRevisions(revisions []models.Revision) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertRevisions(tx, revisions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertRevisions(tx *sql.Tx, revisions []models.Revision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO translation_revisions (id, project_id, file_id, language_code, key, old_value, new_value, author_type, author_id, source, batch_id, source_hash, prompt_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range revisions {
+		if err := setSourceHash(tx, r.FileID, r.Key, r.NewValue, r.SourceHash, r.CreatedAt); err != nil {
+			return err
+		}
+		status := models.StatusForSource(r.Source)
+		if r.NewValue == "" {
+			status = models.StatusUntranslated
+		}
+		if err := setStatus(tx, r.FileID, r.Key, status, r.AuthorID, r.CreatedAt); err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(r.ID, r.ProjectID, r.FileID, r.LanguageCode, r.Key, r.OldValue, r.NewValue, r.AuthorType, r.AuthorID, r.Source, r.BatchID, r.SourceHash, r.PromptID, r.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setSourceHash records which base value a translation was made from. Cleared
+// values and values without a known source drop the record.
+func setSourceHash(tx *sql.Tx, fileID, key, value, hash string, at time.Time) error {
+	if value == "" || hash == "" {
+		_, err := tx.Exec(`DELETE FROM translation_sources WHERE file_id = ? AND key = ?`, fileID, key)
+		return err
+	}
+	_, err := tx.Exec(`INSERT OR REPLACE INTO translation_sources (file_id, key, source_hash, updated_at) VALUES (?, ?, ?, ?)`, fileID, key, hash, at)
+	return err
+}
+
+// SetSourceHashes records the source hash of existing translations, e.g. when a
+// translator confirms a value without changing it
+func (db *DB) SetSourceHashes(fileID string, hashes map[string]string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setSourceHashes(tx, fileID, hashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func setSourceHashes(tx *sql.Tx, fileID string, hashes map[string]string) error {
+	now := time.Now()
+	for key, hash := range hashes {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO translation_sources (file_id, key, source_hash, updated_at) VALUES (?, ?, ?, ?)`, fileID, key, hash, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSourceHashes retrieves the source hash of every translated key of a file
+func (db *DB) GetSourceHashes(fileID string) (map[string]string, error) {
+	rows, err := db.conn.Query(`SELECT key, source_hash FROM translation_sources WHERE file_id = ?`, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]string)
+	for rows.Next() {
+		var key, hash string
+		if err := rows.Scan(&key, &hash); err != nil {
+			return nil, err
+		}
+		hashes[key] = hash
+	}
+	return hashes, rows.Err()
+}
+
+const revisionColumns = `id, project_id, file_id, language_code, key, old_value, new_value, author_type, author_id, source, batch_id, source_hash, prompt_id, created_at`
+
+// GetRevision retrieves a single revision
+func (db *DB) GetRevision(id string) (*models.Revision, error) {
+	row := db.conn.QueryRow(`SELECT `+revisionColumns+` FROM translation_revisions WHERE id = ?`, id)
+
+	var r models.Revision
+	if err := row.Scan(&r.ID, &r.ProjectID, &r.FileID, &r.LanguageCode, &r.Key, &r.OldValue, &r.NewValue, &r.AuthorType, &r.AuthorID, &r.Source, &r.BatchID, &r.SourceHash, &r.PromptID, &r.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetKeyRevisions retrieves the history of a key in a file, newest first
+func (db *DB) GetKeyRevisions(fileID, key string, limit int) ([]models.Revision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM translation_revisions
+	          WHERE file_id = ? AND key = ?
+	          ORDER BY created_at DESC
+	          LIMIT ?`
+	return db.queryRevisions(query, fileID, key, limit)
+}
+
+// GetPreviousRevision retrieves the revision of a key made before the given
+// one, or nil when it is the first
+func (db *DB) GetPreviousRevision(rev *models.Revision) (*models.Revision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM translation_revisions
+	          WHERE file_id = ? AND key = ? AND created_at < (SELECT created_at FROM translation_revisions WHERE id = ?)
+	          ORDER BY created_at DESC
+	          LIMIT 1`
+	revisions, err := db.queryRevisions(query, rev.FileID, rev.Key, rev.ID)
+	if err != nil || len(revisions) == 0 {
+		return nil, err
+	}
+	return &revisions[0], nil
+}
+
+// GetBatchRevisions retrieves all revisions made by one operation
+func (db *DB) GetBatchRevisions(batchID string) ([]models.Revision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM translation_revisions WHERE batch_id = ? ORDER BY key`
+	return db.queryRevisions(query, batchID)
+}
+
+// GetRevisionBatches lists the most recent operations on a project, optionally filtered by source
+func (db *DB) GetRevisionBatches(projectID, source string, limit int) ([]models.RevisionBatch, error) {
+	query := `SELECT batch_id, source, author_type, author_id, COUNT(*), MIN(created_at)
+	          FROM translation_revisions
+	          WHERE project_id = ? AND (? = '' OR source = ?)
+	          GROUP BY batch_id, source, author_type, author_id
+	          ORDER BY MIN(created_at) DESC
+	          LIMIT ?`
+	rows, err := db.conn.Query(query, projectID, source, source, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := []models.RevisionBatch{}
+	for rows.Next() {
+		var b models.RevisionBatch
+		var createdAt string
+		if err := rows.Scan(&b.BatchID, &b.Source, &b.AuthorType, &b.AuthorID, &b.Keys, &createdAt); err != nil {
+			return nil, err
+		}
+		b.CreatedAt = parseTime(createdAt)
+		batches = append(batches, b)
+	}
+	return batches, rows.Err()
+}
+
+func (db *DB) queryRevisions(query string, args ...interface{}) ([]models.Revision, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.Revision{}
+	for rows.Next() {
+		var r models.Revision
+		if err := rows.Scan(&r.ID, &r.ProjectID, &r.FileID, &r.LanguageCode, &r.Key, &r.OldValue, &r.NewValue, &r.AuthorType, &r.AuthorID, &r.Source, &r.BatchID, &r.SourceHash, &r.PromptID, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+// parseTime parses timestamps returned by aggregate functions, which SQLite
+// drivers hand back as text instead of time.Time
+func parseTime(s string) time.Time {
+	// Values written from time.Time.String() carry a monotonic clock suffix
+	if i := strings.Index(s, " m="); i >= 0 {
+		s = s[:i]
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999 -0700 MST", "2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
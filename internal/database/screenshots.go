@@ -0,0 +1,129 @@
+package database
+
+import (
+	"templui/internal/models"
+)
+
+// CreateScreenshot creates a new screenshot record
+func (db *DB) CreateScreenshot(s *models.Screenshot) error {
+	query := `
+		INSERT INTO screenshots (id, project_id, name, storage_key, content_type, width, height, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn.Exec(query, s.ID, s.ProjectID, s.Name, s.StorageKey, s.ContentType, s.Width, s.Height, s.CreatedAt)
+	return err
+}
+
+// GetScreenshot retrieves a screenshot with its regions
+func (db *DB) GetScreenshot(id string) (*models.Screenshot, error) {
+	query := `SELECT id, project_id, name, storage_key, content_type, width, height, created_at FROM screenshots WHERE id = ?`
+	row := db.conn.QueryRow(query, id)
+
+	var s models.Screenshot
+	if err := row.Scan(&s.ID, &s.ProjectID, &s.Name, &s.StorageKey, &s.ContentType, &s.Width, &s.Height, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	regions, err := db.getRegions(`WHERE screenshot_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	s.Regions = regions
+
+	return &s, nil
+}
+
+// GetScreenshotsByProject retrieves all screenshots of a project with their regions
+func (db *DB) GetScreenshotsByProject(projectID string) ([]models.Screenshot, error) {
+	query := `SELECT id, project_id, name, storage_key, content_type, width, height, created_at
+	          FROM screenshots WHERE project_id = ? ORDER BY created_at`
+	rows, err := db.conn.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var screenshots []models.Screenshot
+	index := make(map[string]int)
+	for rows.Next() {
+		var s models.Screenshot
+		if err := rows.Scan(&s.ID, &s.ProjectID, &s.Name, &s.StorageKey, &s.ContentType, &s.Width, &s.Height, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		s.Regions = []models.ScreenshotRegion{}
+		index[s.ID] = len(screenshots)
+		screenshots = append(screenshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	regions, err := db.getRegions(`WHERE project_id = ?`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range regions {
+		if i, ok := index[r.ScreenshotID]; ok {
+			screenshots[i].Regions = append(screenshots[i].Regions, r)
+		}
+	}
+
+	return screenshots, nil
+}
+
+// DeleteScreenshot removes a screenshot and its regions
+func (db *DB) DeleteScreenshot(id string) error {
+	if _, err := db.conn.Exec(`DELETE FROM screenshot_regions WHERE screenshot_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := db.conn.Exec(`DELETE FROM screenshots WHERE id = ?`, id)
+	return err
+}
+
+// CreateScreenshotRegion links a rectangle on a screenshot to a key
+func (db *DB) CreateScreenshotRegion(r *models.ScreenshotRegion) error {
+	query := `
+		INSERT INTO screenshot_regions (id, screenshot_id, project_id, key, x, y, width, height, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn.Exec(query, r.ID, r.ScreenshotID, r.ProjectID, r.Key, r.X, r.Y, r.Width, r.Height, r.CreatedAt)
+	return err
+}
+
+// DeleteScreenshotRegion removes a region from a screenshot
+func (db *DB) DeleteScreenshotRegion(screenshotID, id string) error {
+	_, err := db.conn.Exec(`DELETE FROM screenshot_regions WHERE screenshot_id = ? AND id = ?`, screenshotID, id)
+	return err
+}
+
+// GetScreenshotRegionsByProject retrieves all regions of a project grouped by key
+func (db *DB) GetScreenshotRegionsByProject(projectID string) (map[string][]models.ScreenshotRegion, error) {
+	regions, err := db.getRegions(`WHERE project_id = ?`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string][]models.ScreenshotRegion)
+	for _, r := range regions {
+		byKey[r.Key] = append(byKey[r.Key], r)
+	}
+	return byKey, nil
+}
+
+func (db *DB) getRegions(where string, arg string) ([]models.ScreenshotRegion, error) {
+	query := `SELECT id, screenshot_id, project_id, key, x, y, width, height, created_at FROM screenshot_regions ` + where + ` ORDER BY created_at`
+	rows, err := db.conn.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	regions := []models.ScreenshotRegion{}
+	for rows.Next() {
+		var r models.ScreenshotRegion
+		if err := rows.Scan(&r.ID, &r.ScreenshotID, &r.ProjectID, &r.Key, &r.X, &r.Y, &r.Width, &r.Height, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		regions = append(regions, r)
+	}
+	return regions, rows.Err()
+}
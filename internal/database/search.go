@@ -0,0 +1,230 @@
+package database
+
+import (
+	"strings"
+
+	"templui/internal/jsontools"
+	"templui/internal/models"
+)
+
+// Markers wrapped around matched terms by the FTS5 highlight function
+const (
+	searchMatchStart = "\x02"
+	searchMatchEnd   = "\x03"
+)
+
+// SearchQuery describes a concordance search over the search index
+type SearchQuery struct {
+	Text       string
+	SourceLang string
+	TargetLang string
+	Scope      string // "source", "target" or empty for both
+	ProjectIDs []string
+	Limit      int
+}
+
+// RefreshSearchIndex reindexes the target files of the given projects whose
+// base or target file changed since they were last indexed
+func (db *DB) RefreshSearchIndex(projectIDs []string) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(projectIDs))
+	for i, id := range projectIDs {
+		args[i] = id
+	}
+	rows, err := db.conn.Query(`
+		SELECT t.id
+		FROM files t
+		JOIN files b ON b.project_id = t.project_id AND b.file_type = 'base'
+		LEFT JOIN search_index_files s ON s.file_id = t.id
+		WHERE t.file_type = 'target' AND t.project_id IN (`+placeholders(len(projectIDs))+`)
+			AND (s.file_id IS NULL OR s.target_updated_at IS NOT t.updated_at OR s.base_updated_at IS NOT b.updated_at)
+	`, args...)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		stale = append(stale, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range stale {
+		if err := db.indexFile(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexFile replaces the search segments of a target file
+func (db *DB) indexFile(fileID string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var projectID, targetLang, targetContent, sourceLang, sourceContent string
+	err = tx.QueryRow(`
+		SELECT t.project_id, t.language_code, t.content, b.language_code, b.content
+		FROM files t
+		JOIN files b ON b.project_id = t.project_id AND b.file_type = 'base'
+		WHERE t.id = ?
+	`, fileID).Scan(&projectID, &targetLang, &targetContent, &sourceLang, &sourceContent)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM search_segments WHERE file_id = ?`, fileID); err != nil {
+		return err
+	}
+
+	baseData, _ := jsontools.ParseJSON([]byte(sourceContent))
+	targetData, _ := jsontools.ParseJSON([]byte(targetContent))
+	baseFlat := jsontools.FlattenJSON(baseData, "")
+	targetFlat := jsontools.FlattenJSON(targetData, "")
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO search_segments (project_id, file_id, key, source_lang, target_lang, source_text, target_text)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for key, source := range baseFlat {
+		if _, err := stmt.Exec(projectID, fileID, key, sourceLang, targetLang, source, targetFlat[key]); err != nil {
+			return err
+		}
+	}
+
+	// Record the file versions the segments were built from
+	_, err = tx.Exec(`
+		INSERT INTO search_index_files (file_id, project_id, target_updated_at, base_updated_at)
+		SELECT t.id, t.project_id, t.updated_at, b.updated_at
+		FROM files t
+		JOIN files b ON b.project_id = t.project_id AND b.file_type = 'base'
+		WHERE t.id = ?
+		ON CONFLICT (file_id) DO UPDATE SET
+			target_updated_at = excluded.target_updated_at,
+			base_updated_at = excluded.base_updated_at
+	`, fileID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Search runs a concordance search, best matches first
+func (db *DB) Search(q SearchQuery) ([]models.SearchHit, error) {
+	if len(q.ProjectIDs) == 0 || strings.TrimSpace(q.Text) == "" {
+		return []models.SearchHit{}, nil
+	}
+
+	// Search the text as a phrase, optionally limited to one column
+	match := `"` + strings.ReplaceAll(q.Text, `"`, `""`) + `"`
+	switch q.Scope {
+	case "source":
+		match = "source_text : " + match
+	case "target":
+		match = "target_text : " + match
+	}
+
+	query := `
+		SELECT s.project_id, p.name, s.key, s.source_lang, s.target_lang, s.source_text, s.target_text,
+			highlight(search_index, 0, ?, ?), highlight(search_index, 1, ?, ?)
+		FROM search_index
+		JOIN search_segments s ON s.id = search_index.rowid
+		JOIN projects p ON p.id = s.project_id
+		WHERE search_index MATCH ? AND s.project_id IN (` + placeholders(len(q.ProjectIDs)) + `)`
+	args := []interface{}{searchMatchStart, searchMatchEnd, searchMatchStart, searchMatchEnd, match}
+	for _, id := range q.ProjectIDs {
+		args = append(args, id)
+	}
+	if q.SourceLang != "" {
+		query += ` AND s.source_lang = ?`
+		args = append(args, q.SourceLang)
+	}
+	if q.TargetLang != "" {
+		query += ` AND s.target_lang = ?`
+		args = append(args, q.TargetLang)
+	}
+	query += ` ORDER BY rank, s.key LIMIT ?`
+	args = append(args, q.Limit)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []models.SearchHit{}
+	for rows.Next() {
+		var h models.SearchHit
+		var source, target string
+		if err := rows.Scan(&h.ProjectID, &h.ProjectName, &h.Key, &h.SourceLang, &h.TargetLang, &h.SourceText, &h.TargetText, &source, &target); err != nil {
+			return nil, err
+		}
+		h.SourceSpans = highlightSpans(source)
+		h.TargetSpans = highlightSpans(target)
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+// highlightSpans splits a highlighted value into plain and matched spans
+func highlightSpans(text string) []models.TextSpan {
+	spans := []models.TextSpan{}
+	for text != "" {
+		start := strings.Index(text, searchMatchStart)
+		if start < 0 {
+			spans = append(spans, models.TextSpan{Text: text})
+			break
+		}
+		if start > 0 {
+			spans = append(spans, models.TextSpan{Text: text[:start]})
+		}
+		text = text[start+len(searchMatchStart):]
+		end := strings.Index(text, searchMatchEnd)
+		if end < 0 {
+			end = len(text)
+		}
+		spans = append(spans, models.TextSpan{Text: text[:end], Match: true})
+		text = strings.TrimPrefix(text[end:], searchMatchEnd)
+	}
+	return spans
+}
+
+// placeholders returns n comma-separated SQL parameter placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// GetProjectIDsBySession returns the IDs of all projects created by a session
+func (db *DB) GetProjectIDsBySession(sessionToken string) ([]string, error) {
+	rows, err := db.conn.Query(`SELECT id FROM projects WHERE session_token = ?`, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"templui/internal/models"
+)
+
+// CreateSnapshot stores a snapshot and its files. File contents are written
+// to content-addressed blobs so identical files are only stored once.
+func (db *DB) CreateSnapshot(snapshot *models.Snapshot) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO snapshots (id, project_id, name, description, created_at) VALUES (?, ?, ?, ?, ?)`
+	if _, err := tx.Exec(query, snapshot.ID, snapshot.ProjectID, snapshot.Name, snapshot.Description, snapshot.CreatedAt); err != nil {
+		return err
+	}
+
+	for i := range snapshot.Files {
+		f := &snapshot.Files[i]
+		hash := sha256.Sum256([]byte(f.Content))
+		f.BlobHash = hex.EncodeToString(hash[:])
+		f.SnapshotID = snapshot.ID
+
+		compressed, err := compress(f.Content)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO snapshot_blobs (hash, content, size) VALUES (?, ?, ?)`, f.BlobHash, compressed, len(f.Content)); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO snapshot_files (snapshot_id, file_type, language_code, blob_hash) VALUES (?, ?, ?, ?)`, snapshot.ID, f.FileType, f.LanguageCode, f.BlobHash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSnapshot retrieves a snapshot by ID or name, including file contents
+func (db *DB) GetSnapshot(projectID, idOrName string) (*models.Snapshot, error) {
+	query := `SELECT id, project_id, name, description, created_at FROM snapshots
+	          WHERE project_id = ? AND (id = ? OR name = ?)`
+	row := db.conn.QueryRow(query, projectID, idOrName, idOrName)
+
+	var s models.Snapshot
+	if err := row.Scan(&s.ID, &s.ProjectID, &s.Name, &s.Description, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT f.file_type, f.language_code, f.blob_hash, b.content
+		FROM snapshot_files f JOIN snapshot_blobs b ON b.hash = f.blob_hash
+		WHERE f.snapshot_id = ?
+		ORDER BY f.file_type, f.language_code`, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		f := models.SnapshotFile{SnapshotID: s.ID}
+		var compressed []byte
+		if err := rows.Scan(&f.FileType, &f.LanguageCode, &f.BlobHash, &compressed); err != nil {
+			return nil, err
+		}
+		if f.Content, err = decompress(compressed); err != nil {
+			return nil, err
+		}
+		s.Files = append(s.Files, f)
+	}
+
+	return &s, rows.Err()
+}
+
+// ListSnapshots retrieves all snapshots of a project, newest first, with their
+// file list but without file contents
+func (db *DB) ListSnapshots(projectID string) ([]models.Snapshot, error) {
+	query := `SELECT id, project_id, name, description, created_at FROM snapshots
+	          WHERE project_id = ? ORDER BY created_at DESC`
+	rows, err := db.conn.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []models.Snapshot{}
+	for rows.Next() {
+		var s models.Snapshot
+		if err := rows.Scan(&s.ID, &s.ProjectID, &s.Name, &s.Description, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range snapshots {
+		files, err := db.getSnapshotFiles(snapshots[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		snapshots[i].Files = files
+	}
+	return snapshots, nil
+}
+
+// getSnapshotFiles lists the files of a snapshot without their contents
+func (db *DB) getSnapshotFiles(snapshotID string) ([]models.SnapshotFile, error) {
+	query := `SELECT file_type, language_code, blob_hash FROM snapshot_files
+	          WHERE snapshot_id = ? ORDER BY file_type, language_code`
+	rows, err := db.conn.Query(query, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := []models.SnapshotFile{}
+	for rows.Next() {
+		f := models.SnapshotFile{SnapshotID: snapshotID}
+		if err := rows.Scan(&f.FileType, &f.LanguageCode, &f.BlobHash); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+func compress(content string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) (string, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+	content, err := io.ReadAll(zr)
+	return string(content), err
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"templui/internal/models"
+)
+
+// GetStatuses retrieves the stored workflow statuses of a file, keyed by translation key
+func (db *DB) GetStatuses(fileID string) (map[string]models.TranslationStatus, error) {
+	query := `SELECT file_id, key, status, updated_by, updated_at FROM translation_statuses WHERE file_id = ?`
+	rows, err := db.conn.Query(query, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := make(map[string]models.TranslationStatus)
+	for rows.Next() {
+		var s models.TranslationStatus
+		if err := rows.Scan(&s.FileID, &s.Key, &s.Status, &s.UpdatedBy, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		statuses[s.Key] = s
+	}
+	return statuses, rows.Err()
+}
+
+// SetStatus stores the workflow status of a key
+func (db *DB) SetStatus(fileID, key, status, updatedBy string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setStatus(tx, fileID, key, status, updatedBy, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// setStatus stores a status; untranslated keys drop their row since the
+// status follows from the empty value
+func setStatus(tx *sql.Tx, fileID, key, status, updatedBy string, at time.Time) error {
+	if status == models.StatusUntranslated {
+		_, err := tx.Exec(`DELETE FROM translation_statuses WHERE file_id = ? AND key = ?`, fileID, key)
+		return err
+	}
+	query := `INSERT OR REPLACE INTO translation_statuses (file_id, key, status, updated_by, updated_at) VALUES (?, ?, ?, ?, ?)`
+	_, err := tx.Exec(query, fileID, key, status, updatedBy, at)
+	return err
+}
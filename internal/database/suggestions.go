@@ -0,0 +1,86 @@
+package database
+
+import (
+	"time"
+
+	"templui/internal/models"
+)
+
+const suggestionQuery = `
+	SELECT s.id, s.project_id, s.file_id, s.key, s.value, s.author_type, s.author_id, s.status, s.created_at,
+	       (SELECT COUNT(*) FROM suggestion_votes v WHERE v.suggestion_id = s.id) AS votes,
+	       EXISTS (SELECT 1 FROM suggestion_votes v WHERE v.suggestion_id = s.id AND v.voter_id = ?) AS has_voted
+	FROM suggestions s`
+
+// CreateSuggestion stores a new suggestion
+func (db *DB) CreateSuggestion(s *models.Suggestion) error {
+	query := `INSERT INTO suggestions (id, project_id, file_id, key, value, author_type, author_id, status, created_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := db.conn.Exec(query, s.ID, s.ProjectID, s.FileID, s.Key, s.Value, s.AuthorType, s.AuthorID, s.Status, s.CreatedAt)
+	return err
+}
+
+// GetSuggestion retrieves a suggestion; voterID determines HasVoted
+func (db *DB) GetSuggestion(id, voterID string) (*models.Suggestion, error) {
+	row := db.conn.QueryRow(suggestionQuery+` WHERE s.id = ?`, voterID, id)
+
+	var s models.Suggestion
+	if err := row.Scan(&s.ID, &s.ProjectID, &s.FileID, &s.Key, &s.Value, &s.AuthorType, &s.AuthorID, &s.Status, &s.CreatedAt, &s.Votes, &s.HasVoted); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetSuggestions retrieves the suggestions for a key, open ones first and most
+// upvoted first; voterID determines HasVoted
+func (db *DB) GetSuggestions(fileID, key, voterID string) ([]models.Suggestion, error) {
+	query := suggestionQuery + ` WHERE s.file_id = ? AND s.key = ?
+	          ORDER BY CASE s.status WHEN 'open' THEN 0 ELSE 1 END, votes DESC, s.created_at`
+	rows, err := db.conn.Query(query, voterID, fileID, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []models.Suggestion{}
+	for rows.Next() {
+		var s models.Suggestion
+		if err := rows.Scan(&s.ID, &s.ProjectID, &s.FileID, &s.Key, &s.Value, &s.AuthorType, &s.AuthorID, &s.Status, &s.CreatedAt, &s.Votes, &s.HasVoted); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
+
+// CountOpenSuggestions counts the open suggestions of a file by key
+func (db *DB) CountOpenSuggestions(fileID string) (map[string]int, error) {
+	rows, err := db.conn.Query(`SELECT key, COUNT(*) FROM suggestions WHERE file_id = ? AND status = 'open' GROUP BY key`, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var key string
+		var n int
+		if err := rows.Scan(&key, &n); err != nil {
+			return nil, err
+		}
+		counts[key] = n
+	}
+	return counts, rows.Err()
+}
+
+// VoteSuggestion records an upvote; voting twice has no effect
+func (db *DB) VoteSuggestion(suggestionID, voterID string) error {
+	_, err := db.conn.Exec(`INSERT OR IGNORE INTO suggestion_votes (suggestion_id, voter_id, created_at) VALUES (?, ?, ?)`, suggestionID, voterID, time.Now())
+	return err
+}
+
+// SetSuggestionStatus marks a suggestion as accepted or dismissed
+func (db *DB) SetSuggestionStatus(id, status string) error {
+	_, err := db.conn.Exec(`UPDATE suggestions SET status = ? WHERE id = ?`, status, id)
+	return err
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"templui/internal/models"
+)
+
+// Groupings of AI usage totals
+const (
+	UsageByProject    = "project_id"
+	UsageByTranslator = "translator"
+	UsageByAuthor     = "author_type || ':' || author_id"
+	UsageByDay        = "substr(created_at, 1, 10)"
+)
+
+// RecordAIUsage stores the usage of one machine translation request
+func (db *DB) RecordAIUsage(u *models.AIUsage) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO ai_usage (id, project_id, job_id, batch_id, author_type, author_id, translator,
+			prompt_tokens, completion_tokens, characters, cost, month, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, u.ID, u.ProjectID, u.JobID, u.BatchID, u.AuthorType, u.AuthorID, u.Translator,
+		u.PromptTokens, u.CompletionTokens, u.Characters, u.Cost, u.Month, u.CreatedAt)
+	return err
+}
+
+// AIUsageCost returns the estimated AI cost of a project in a month, or of
+// all projects when projectID is empty
+func (db *DB) AIUsageCost(projectID, month string) (float64, error) {
+	query := `SELECT COALESCE(SUM(cost), 0) FROM ai_usage WHERE month = ?`
+	args := []interface{}{month}
+	if projectID != "" {
+		query += ` AND project_id = ?`
+		args = append(args, projectID)
+	}
+	var cost float64
+	err := db.conn.QueryRow(query, args...).Scan(&cost)
+	return cost, err
+}
+
+// AIUsageTotals sums the AI usage of the given projects in a month, grouped
+// by one of the UsageBy groupings
+func (db *DB) AIUsageTotals(projectIDs []string, month, groupBy string) ([]models.UsageTotal, error) {
+	totals := []models.UsageTotal{}
+	if len(projectIDs) == 0 {
+		return totals, nil
+	}
+	args := []interface{}{month}
+	for _, id := range projectIDs {
+		args = append(args, id)
+	}
+	rows, err := db.conn.Query(`
+		SELECT `+groupBy+`, COUNT(*), SUM(prompt_tokens), SUM(completion_tokens), SUM(characters), SUM(cost)
+		FROM ai_usage WHERE month = ? AND project_id IN (`+placeholders(len(projectIDs))+`)
+		GROUP BY 1 ORDER BY 6 DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.UsageTotal
+		if err := rows.Scan(&t.Group, &t.Requests, &t.PromptTokens, &t.CompletionTokens, &t.Characters, &t.Cost); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+// GetRecentAIUsage lists the latest AI requests of a project
+func (db *DB) GetRecentAIUsage(projectID string, limit int) ([]models.AIUsage, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, project_id, job_id, batch_id, author_type, author_id, translator,
+			prompt_tokens, completion_tokens, characters, cost, month, created_at
+		FROM ai_usage WHERE project_id = ? ORDER BY created_at DESC LIMIT ?
+	`, projectID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []models.AIUsage{}
+	for rows.Next() {
+		var u models.AIUsage
+		if err := rows.Scan(&u.ID, &u.ProjectID, &u.JobID, &u.BatchID, &u.AuthorType, &u.AuthorID, &u.Translator,
+			&u.PromptTokens, &u.CompletionTokens, &u.Characters, &u.Cost, &u.Month, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
@@ -0,0 +1,100 @@
+// Package events fans out live project events, such as job progress and keys
+// saved by collaborators, to the editors that have the project open. Events
+// are kept in memory, so only subscribers on the same server receive them.
+package events
+
+import (
+	"sync"
+
+	"templui/internal/models"
+	"templui/internal/qa"
+)
+
+// Event types
+const (
+	TypeJob        = "job"        // A background job changed, Data is a JobEvent
+	TypeKeys       = "keys"       // Translations were saved, Data is a KeysEvent
+	TypeValidation = "validation" // Validation results of saved keys, Data is a ValidationEvent
+	TypeQuality    = "quality"    // AI quality assessments were stored, Data is a KeysEvent
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind
+// before events are dropped for it
+const subscriberBuffer = 64
+
+// Event is a single project event
+type Event struct {
+	Type string
+	Data interface{}
+}
+
+// JobEvent is the state of a background job. Parameters and results are left
+// out, subscribers load the job for those.
+type JobEvent struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Done   int    `json:"done"`
+	Total  int    `json:"total"`
+}
+
+// NewJobEvent returns the event for the current state of a job
+func NewJobEvent(job *models.Job) JobEvent {
+	return JobEvent{ID: job.ID, Type: job.Type, Status: job.Status, Done: job.Done, Total: job.Total}
+}
+
+// KeysEvent lists keys whose translation changed
+type KeysEvent struct {
+	Language   string   `json:"language"`
+	Keys       []string `json:"keys"`
+	AuthorType string   `json:"author_type"`
+	AuthorID   string   `json:"author_id"`
+	Source     string   `json:"source"`
+	BatchID    string   `json:"batch_id,omitempty"`
+}
+
+// ValidationEvent holds the validation results of a saved or rejected key
+type ValidationEvent struct {
+	Key    string     `json:"key"`
+	Issues []qa.Issue `json:"issues,omitempty"` // QA findings of the saved value
+	Error  string     `json:"error,omitempty"`  // Why an AI translation was rejected or needs review
+}
+
+var (
+	mu          sync.Mutex
+	subscribers = make(map[string]map[chan Event]struct{})
+)
+
+// Subscribe returns a channel receiving the events of a project and a
+// function that ends the subscription
+func Subscribe(projectID string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	mu.Lock()
+	if subscribers[projectID] == nil {
+		subscribers[projectID] = make(map[chan Event]struct{})
+	}
+	subscribers[projectID][ch] = struct{}{}
+	mu.Unlock()
+
+	return ch, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(subscribers[projectID], ch)
+		if len(subscribers[projectID]) == 0 {
+			delete(subscribers, projectID)
+		}
+	}
+}
+
+// Publish sends an event to all subscribers of a project without blocking
+func Publish(projectID string, ev Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	for ch := range subscribers[projectID] {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
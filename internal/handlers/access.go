@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/models"
+	"templui/internal/session"
+)
+
+// apiKeyCookie remembers the share link key a browser opened the editor with
+func apiKeyCookie(projectID string) string {
+	return "project_key_" + projectID
+}
+
+// requestAPIKey returns the project API key the request carries, either in the
+// X-API-Key header, the ?key= of a share link or the cookie set when a share
+// link was opened
+func requestAPIKey(c echo.Context, db *database.DB, projectID string) *models.APIKey {
+	var keyHash string
+	if key := c.Request().Header.Get("X-API-Key"); key != "" {
+		hash := sha256.Sum256([]byte(key))
+		keyHash = hex.EncodeToString(hash[:])
+	} else if key := c.QueryParam("key"); key != "" {
+		hash := sha256.Sum256([]byte(key))
+		keyHash = hex.EncodeToString(hash[:])
+	} else if cookie, err := c.Cookie(apiKeyCookie(projectID)); err == nil {
+		keyHash = cookie.Value
+	}
+	if keyHash == "" {
+		return nil
+	}
+
+	apiKey, err := db.GetAPIKeyByHash(keyHash)
+	if err != nil || apiKey.ProjectID != projectID {
+		return nil
+	}
+	if apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(time.Now()) {
+		return nil
+	}
+	return apiKey
+}
+
+// rememberAPIKey stores the share link key from the ?key= query parameter in a
+// cookie so later editor requests carry its permissions
+func rememberAPIKey(c echo.Context, db *database.DB, projectID string) {
+	key := c.QueryParam("key")
+	if key == "" {
+		return
+	}
+	hash := sha256.Sum256([]byte(key))
+	keyHash := hex.EncodeToString(hash[:])
+
+	apiKey, err := db.GetAPIKeyByHash(keyHash)
+	if err != nil || apiKey.ProjectID != projectID {
+		return
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     apiKeyCookie(projectID),
+		Value:    keyHash,
+		Path:     "/",
+		Expires:  time.Now().Add(24 * time.Hour * 30),
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// requestRole determines the caller's workflow role in a project. Callers
+// that cannot be identified get the most restricted role; only the owner's
+// session, the project's secret key and keys with the "write" permission may
+// change translations directly.
+func requestRole(c echo.Context, db *database.DB, project *models.Project) string {
+	if token := session.GetSessionToken(c); token != "" && token == project.SessionToken {
+		return models.RoleOwner
+	}
+	apiKey := requestAPIKey(c, db, project.ID)
+	switch {
+	case apiKey != nil && apiKey.HasPermission("review"):
+		return models.RoleReviewer
+	case apiKey != nil && apiKey.HasPermission("write"):
+		return models.RoleTranslator
+	case hasSecretKey(c, project):
+		return models.RoleTranslator
+	default:
+		return models.RoleSuggester
+	}
+}
+
+// hasSecretKey reports whether the request carries the secret key of a locked
+// project, either in the cookie set by the auth page or as ?key=
+func hasSecretKey(c echo.Context, project *models.Project) bool {
+	if project.SecretKeyHash == "" {
+		return false
+	}
+	if cookie, err := c.Cookie("project_auth_" + project.ID); err == nil && cookie.Value == project.SecretKeyHash {
+		return true
+	}
+	if key := c.QueryParam("key"); key != "" {
+		hash := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(hash[:]) == project.SecretKeyHash
+	}
+	return false
+}
+
+// requireEditor responds with 403 when the caller can only suggest
+// translations, and reports whether the request may go on
+func requireEditor(c echo.Context, db *database.DB, project *models.Project) bool {
+	if requestRole(c, db, project) == models.RoleSuggester {
+		c.JSON(http.StatusForbidden, map[string]string{"error": "This link can only suggest translations"})
+		return false
+	}
+	return true
+}
+
+// canSuggest reports whether the caller may suggest translations and vote on
+// suggestions: the owner, holders of the secret key and share links with the
+// "suggest", "write" or "review" permission. Locked projects also require the
+// secret key or the owner's session.
+func canSuggest(c echo.Context, db *database.DB, project *models.Project) bool {
+	if project.IsLocked && !isAuthenticated(c, project) {
+		return false
+	}
+	if requestRole(c, db, project) != models.RoleSuggester {
+		return true
+	}
+	apiKey := requestAPIKey(c, db, project.ID)
+	return apiKey != nil && apiKey.HasPermission("suggest")
+}
+
+// accessibleProjects returns the IDs of the projects a request may read: those
+// created by its session, and those it holds a share link or secret key for
+func accessibleProjects(c echo.Context, db *database.DB) ([]string, error) {
+	var ids []string
+	if token := session.GetSessionToken(c); token != "" {
+		owned, err := db.GetProjectIDsBySession(token)
+		if err != nil {
+			return nil, err
+		}
+		ids = owned
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+
+	add := func(projectID string, allowed func(*models.Project) bool) {
+		if projectID == "" || seen[projectID] {
+			return
+		}
+		if project, err := db.GetProject(projectID); err == nil && allowed(project) {
+			seen[projectID] = true
+			ids = append(ids, projectID)
+		}
+	}
+	if key := c.Request().Header.Get("X-API-Key"); key != "" {
+		hash := sha256.Sum256([]byte(key))
+		if apiKey, err := db.GetAPIKeyByHash(hex.EncodeToString(hash[:])); err == nil {
+			add(apiKey.ProjectID, func(p *models.Project) bool { return requestAPIKey(c, db, p.ID) != nil })
+		}
+	}
+	for _, cookie := range c.Cookies() {
+		if id, ok := strings.CutPrefix(cookie.Name, apiKeyCookie("")); ok {
+			add(id, func(p *models.Project) bool { return requestAPIKey(c, db, p.ID) != nil })
+		} else if id, ok := strings.CutPrefix(cookie.Name, "project_auth_"); ok {
+			add(id, func(p *models.Project) bool { return isAuthenticated(c, p) })
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/jsontools"
+	"templui/internal/models"
+	"templui/internal/session"
+	"templui/ui/pages"
+)
+
+// UpdateBaseRequest represents the request body for replacing the base file
+type UpdateBaseRequest struct {
+	BaseFile string            `json:"base_file" form:"base_file"`
+	Renames  map[string]string `json:"renames"` // Optional old → new key mapping replacing detected renames
+}
+
+// baseUpdate is a planned replacement of a project's base file
+type baseUpdate struct {
+	Project  *models.Project
+	BaseFile *models.TranslationFile
+	Targets  []*models.TranslationFile
+	BaseFlat map[string]string
+	Content  string // New base content with ARB metadata removed
+	Meta     map[string]models.KeyMetadata
+	Plan     jsontools.BaseUpdatePlan
+	Migrated map[string]map[string]string // Migrated target values by language
+	Review   map[string][]string          // Keys needing review by language
+}
+
+// prepareBaseUpdate validates the request and plans the update without saving anything
+func (h *ProjectHandler) prepareBaseUpdate(c echo.Context) (*baseUpdate, int, error) {
+	projectID := c.Param("id")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return nil, http.StatusNotFound, fmt.Errorf("project not found")
+	}
+	if project.SessionToken != session.GetSessionToken(c) {
+		return nil, http.StatusForbidden, fmt.Errorf("unauthorized")
+	}
+
+	var req UpdateBaseRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid request")
+	}
+	if err := jsontools.ValidateTranslationFile([]byte(req.BaseFile)); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid base file: %v", err)
+	}
+	content, meta, err := stripARBMetadata(req.BaseFile)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid base file: %v", err)
+	}
+
+	files, err := h.db.GetFilesByProject(projectID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to get files")
+	}
+
+	u := &baseUpdate{
+		Project:  project,
+		Content:  content,
+		Meta:     meta,
+		Migrated: make(map[string]map[string]string),
+		Review:   make(map[string][]string),
+	}
+	for i := range files {
+		if files[i].FileType == "base" {
+			u.BaseFile = &files[i]
+		} else {
+			u.Targets = append(u.Targets, &files[i])
+		}
+	}
+	if u.BaseFile == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("base file not found")
+	}
+
+	oldData, _ := jsontools.ParseJSON([]byte(u.BaseFile.Content))
+	newData, _ := jsontools.ParseJSON([]byte(content))
+	u.BaseFlat = jsontools.FlattenJSON(oldData, "")
+	u.Plan, err = jsontools.PlanBaseUpdate(u.BaseFlat, jsontools.FlattenJSON(newData, ""), req.Renames)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	for _, target := range u.Targets {
+		targetData, _ := jsontools.ParseJSON([]byte(target.Content))
+		migrated, review := u.Plan.MigrateTarget(jsontools.FlattenJSON(targetData, ""))
+		u.Migrated[target.LanguageCode] = migrated
+		u.Review[target.LanguageCode] = review
+	}
+
+	return u, http.StatusOK, nil
+}
+
+// response summarizes the planned changes
+func (u *baseUpdate) response() map[string]interface{} {
+	languages := make(map[string]interface{}, len(u.Review))
+	for lang, review := range u.Review {
+		languages[lang] = map[string]interface{}{"needs_review": review}
+	}
+	return map[string]interface{}{
+		"plan":      u.Plan,
+		"languages": languages,
+	}
+}
+
+// PreviewBaseUpdate handles POST /api/project/:id/base/preview
+func (h *ProjectHandler) PreviewBaseUpdate(c echo.Context) error {
+	u, status, err := h.prepareBaseUpdate(c)
+	if err != nil {
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return render(c, pages.BaseUpdatePreview(u.Project.ID, u.Plan, u.Review))
+	}
+	return c.JSON(http.StatusOK, u.response())
+}
+
+// UpdateBaseFile handles POST /api/project/:id/base
+// Replaces the base file, migrates all target languages to the new keys and
+// flags translations whose source text changed for review.
+func (h *ProjectHandler) UpdateBaseFile(c echo.Context) error {
+	u, status, err := h.prepareBaseUpdate(c)
+	if err != nil {
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+	projectID := u.Project.ID
+
+	update := database.BaseUpdate{
+		ProjectID: projectID,
+		Base:      database.FileUpdate{ID: u.BaseFile.ID, Content: u.Content},
+		Renames:   make(map[string]string, len(u.Plan.Renamed)),
+	}
+	renamedFrom := make(map[string]string, len(u.Plan.Renamed))
+	for _, r := range u.Plan.Renamed {
+		update.Renames[r.From] = r.To
+		renamedFrom[r.To] = r.From
+	}
+
+	// Migrated translations were made from the old source text, so renamed
+	// keys keep the source of the key they came from
+	sources := maps.Clone(u.BaseFlat)
+	for to, from := range renamedFrom {
+		sources[to] = u.BaseFlat[from]
+	}
+	for key := range u.Plan.Removed {
+		update.Removed = append(update.Removed, key)
+	}
+
+	author := requestAuthor(c, h.db, projectID)
+	now := time.Now()
+	for _, target := range u.Targets {
+		migrated := u.Migrated[target.LanguageCode]
+		content, err := json.MarshalIndent(jsontools.UnflattenJSON(migrated), "", "  ")
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to build target file"})
+		}
+
+		// Keys that disappear are recorded as cleared
+		targetData, _ := jsontools.ParseJSON([]byte(target.Content))
+		before := jsontools.FlattenJSON(targetData, "")
+		history := make(map[string]string, len(migrated))
+		for key := range before {
+			history[key] = ""
+		}
+		for key, value := range migrated {
+			history[key] = value
+		}
+
+		fileUpdate := database.FileUpdate{
+			ID:           target.ID,
+			Content:      string(content),
+			Revisions:    buildRevisions(target, sources, before, history, author, models.SourceBaseUpdate),
+			SourceHashes: make(map[string]string),
+			Statuses:     make(map[string]string),
+		}
+
+		for _, key := range u.Review[target.LanguageCode] {
+			fileUpdate.SourceHashes[key] = jsontools.SourceHash(sources[key])
+			fileUpdate.Statuses[key] = models.StatusNeedsReview
+			flag := models.ReviewFlag{
+				ProjectID:    projectID,
+				LanguageCode: target.LanguageCode,
+				Key:          key,
+				Reason:       models.ReviewSourceChanged,
+				OldSource:    u.BaseFlat[key],
+				CreatedAt:    now,
+			}
+			if from, ok := renamedFrom[key]; ok {
+				flag.Reason = models.ReviewRenamed
+				flag.OldSource = u.BaseFlat[from]
+			}
+			update.ReviewFlags = append(update.ReviewFlags, flag)
+		}
+		update.Targets = append(update.Targets, fileUpdate)
+	}
+
+	if err := h.db.ApplyBaseUpdate(update); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update base file"})
+	}
+	if err := saveKeyMetadata(h.db, projectID, u.Meta); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save key metadata"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		c.Response().Header().Set("HX-Refresh", "true")
+	}
+	return c.JSON(http.StatusOK, u.response())
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/models"
+	"templui/ui/pages"
+)
+
+// collaboratorName restricts display names to what an @mention can match
+var collaboratorName = regexp.MustCompile(`^[\p{L}\p{N}_.-]{1,32}$`)
+
+var mentionPattern = regexp.MustCompile(`@([\p{L}\p{N}_.-]+)`)
+
+type CommentHandler struct {
+	db *database.DB
+}
+
+func NewCommentHandler(db *database.DB) *CommentHandler {
+	return &CommentHandler{db: db}
+}
+
+// parseMentions returns the collaborator names mentioned in a comment body.
+// Unknown names are ignored; matching is case-insensitive.
+func parseMentions(body string, collaborators []models.Collaborator) []string {
+	mentions := []string{}
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		for _, collaborator := range collaborators {
+			if strings.EqualFold(match[1], collaborator.Name) && !seen[collaborator.Name] {
+				seen[collaborator.Name] = true
+				mentions = append(mentions, collaborator.Name)
+			}
+		}
+	}
+	return mentions
+}
+
+// viewerName returns the display name the current session chose, if any
+func (h *CommentHandler) viewerName(c echo.Context, projectID string) string {
+	if collaborator, err := h.db.GetCollaborator(projectID, sessionFingerprint(c)); err == nil {
+		return collaborator.Name
+	}
+	return ""
+}
+
+// renderComments renders the comment panel of a key, or returns its threads as JSON
+func (h *CommentHandler) renderComments(c echo.Context, data *projectData, key string, status int, errorMessage string) error {
+	filter := database.CommentFilter{Key: key, LanguageCode: data.TargetFile.LanguageCode}
+	threads, err := h.db.GetCommentThreads(data.Project.ID, filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get comments"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return render(c, pages.CommentList(data.Project.ID, key, data.TargetFile.LanguageCode, threads, h.viewerName(c, data.Project.ID), errorMessage))
+	}
+	if errorMessage != "" {
+		return c.JSON(status, map[string]string{"error": errorMessage})
+	}
+	return c.JSON(status, threads)
+}
+
+// ListComments handles GET /api/project/:id/comments
+// Optional filters: ?key=, ?language=, ?status=open|resolved and ?mention=<name>.
+func (h *CommentHandler) ListComments(c echo.Context) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return h.renderComments(c, data, c.QueryParam("key"), http.StatusOK, "")
+	}
+
+	filter := database.CommentFilter{
+		Key:          c.QueryParam("key"),
+		LanguageCode: c.QueryParam("language"),
+		Status:       c.QueryParam("status"),
+		Mention:      c.QueryParam("mention"),
+	}
+	threads, err := h.db.GetCommentThreads(data.Project.ID, filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get comments"})
+	}
+	return c.JSON(http.StatusOK, threads)
+}
+
+// CreateCommentRequest represents the request body for posting a comment.
+// Without ThreadID a new thread is started on Key.
+type CreateCommentRequest struct {
+	Key          string `json:"key"`
+	LanguageCode string `json:"language_code"` // Limits a new thread to one language
+	ThreadID     string `json:"thread_id"`
+	Body         string `json:"body"`
+	AuthorName   string `json:"author_name"` // Sets the poster's display name
+}
+
+// CreateComment handles POST /api/project/:id/comments
+// Accepts a JSON body, or from the editor ?key= (and ?thread= for replies) with
+// the text in the "comment:<key>" or "reply:<thread>" form field.
+func (h *CommentHandler) CreateComment(c echo.Context) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	var req CreateCommentRequest
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		}
+	} else {
+		req.Key = c.QueryParam("key")
+		req.ThreadID = c.QueryParam("thread")
+		if req.ThreadID != "" {
+			req.Body = c.FormValue("reply:" + req.ThreadID)
+		} else {
+			req.Body = c.FormValue("comment:" + req.Key)
+			req.LanguageCode = c.FormValue("comment_lang:" + req.Key)
+		}
+		req.AuthorName = c.FormValue("author_name:" + req.Key)
+	}
+	req.Body = strings.TrimSpace(req.Body)
+	req.AuthorName = strings.TrimSpace(req.AuthorName)
+
+	var thread *models.CommentThread
+	if req.ThreadID != "" {
+		thread, err = h.db.GetCommentThread(req.ThreadID)
+		if err != nil || thread.ProjectID != data.Project.ID {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Thread not found"})
+		}
+		req.Key = thread.Key
+	} else if _, ok := data.BaseFlat[req.Key]; !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Key not found"})
+	}
+	if req.LanguageCode != "" && req.LanguageCode != data.TargetFile.LanguageCode {
+		return h.renderComments(c, data, req.Key, http.StatusBadRequest, "Unknown language: "+req.LanguageCode)
+	}
+	if req.Body == "" {
+		return h.renderComments(c, data, req.Key, http.StatusBadRequest, "A comment cannot be empty")
+	}
+
+	authorID := sessionFingerprint(c)
+	if req.AuthorName != "" {
+		if !collaboratorName.MatchString(req.AuthorName) {
+			return h.renderComments(c, data, req.Key, http.StatusBadRequest, "Names may only contain letters, digits, '.', '_' and '-'")
+		}
+		if err := h.db.SetCollaboratorName(data.Project.ID, authorID, req.AuthorName); err != nil {
+			return h.renderComments(c, data, req.Key, http.StatusConflict, "This name is already taken")
+		}
+	}
+	authorName := h.viewerName(c, data.Project.ID)
+	if authorName == "" {
+		return h.renderComments(c, data, req.Key, http.StatusBadRequest, "Choose a name before commenting")
+	}
+
+	collaborators, err := h.db.GetCollaborators(data.Project.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get collaborators"})
+	}
+
+	now := time.Now()
+	comment := &models.Comment{
+		ID:         generateID(),
+		AuthorType: models.AuthorSession,
+		AuthorID:   authorID,
+		AuthorName: authorName,
+		Body:       req.Body,
+		Mentions:   parseMentions(req.Body, collaborators),
+		CreatedAt:  now,
+	}
+	if thread != nil {
+		comment.ThreadID = thread.ID
+		err = h.db.AddComment(comment)
+	} else {
+		thread = &models.CommentThread{
+			ID:           generateID(),
+			ProjectID:    data.Project.ID,
+			Key:          req.Key,
+			LanguageCode: req.LanguageCode,
+			CreatedAt:    now,
+		}
+		comment.ThreadID = thread.ID
+		err = h.db.CreateCommentThread(thread, comment)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save comment"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return h.renderComments(c, data, req.Key, http.StatusCreated, "")
+	}
+	return c.JSON(http.StatusCreated, comment)
+}
+
+// ResolveThread handles POST /api/project/:id/comments/:tid/resolve
+func (h *CommentHandler) ResolveThread(c echo.Context) error {
+	return h.setResolved(c, true)
+}
+
+// ReopenThread handles POST /api/project/:id/comments/:tid/reopen
+func (h *CommentHandler) ReopenThread(c echo.Context) error {
+	return h.setResolved(c, false)
+}
+
+// setResolved changes the state of a thread. Suggesters may only resolve
+// threads they started.
+func (h *CommentHandler) setResolved(c echo.Context, resolved bool) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	thread, err := h.db.GetCommentThread(c.Param("tid"))
+	if err != nil || thread.ProjectID != data.Project.ID {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Thread not found"})
+	}
+
+	starter := len(thread.Comments) > 0 && thread.Comments[0].AuthorID == sessionFingerprint(c)
+	if requestRole(c, h.db, data.Project) == models.RoleSuggester && !starter {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only the author can resolve this thread"})
+	}
+
+	resolvedBy := h.viewerName(c, data.Project.ID)
+	if resolvedBy == "" {
+		resolvedBy = sessionFingerprint(c)
+	}
+	if err := h.db.SetCommentThreadResolved(thread.ID, resolved, resolvedBy); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update thread"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return h.renderComments(c, data, thread.Key, http.StatusOK, "")
+	}
+	thread, err = h.db.GetCommentThread(thread.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get thread"})
+	}
+	return c.JSON(http.StatusOK, thread)
+}
+
+// ListCollaborators handles GET /api/project/:id/collaborators
+func (h *CommentHandler) ListCollaborators(c echo.Context) error {
+	collaborators, err := h.db.GetCollaborators(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get collaborators"})
+	}
+	return c.JSON(http.StatusOK, collaborators)
+}
+
+// SetNameRequest represents the request body for choosing a display name
+type SetNameRequest struct {
+	Name string `json:"name"`
+}
+
+// SetCollaboratorName handles PUT /api/project/:id/collaborators/me
+func (h *CommentHandler) SetCollaboratorName(c echo.Context) error {
+	projectID := c.Param("id")
+	if _, err := h.db.GetProject(projectID); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	var req SetNameRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !collaboratorName.MatchString(req.Name) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Names may only contain letters, digits, '.', '_' and '-'"})
+	}
+
+	if err := h.db.SetCollaboratorName(projectID, sessionFingerprint(c), req.Name); err != nil {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "This name is already taken"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"name": req.Name})
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"net/http"
-	"sort"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -28,7 +27,8 @@ func NewEditorHandler(db *database.DB) *EditorHandler {
 // Editor handles GET /project/:id/edit
 func (h *EditorHandler) Editor(c echo.Context) error {
 	projectID := c.Param("id")
-	viewMode := c.QueryParam("view") // "missing" or "full"
+	viewMode := c.QueryParam("view")       // "missing", "outdated", "questions", "low_confidence" or "full"
+	statusFilter := c.QueryParam("status") // Optional workflow status filter
 
 	// Get project
 	project, err := h.db.GetProject(projectID)
@@ -39,59 +39,28 @@ func (h *EditorHandler) Editor(c echo.Context) error {
 	// Check if project is locked
 	if project.IsLocked {
 		// Check for valid authentication
-		if !h.isAuthenticated(c, project) {
+		if !isAuthenticated(c, project) {
 			// Redirect to auth page
 			return c.Redirect(http.StatusFound, "/project/"+projectID+"/auth")
 		}
 	}
 
+	// Remember share link permissions for later requests
+	rememberAPIKey(c, h.db, projectID)
+
 	// Get files
-	files, err := h.db.GetFilesByProject(projectID)
+	data, err := loadProjectData(h.db, projectID)
 	if err != nil {
-		return c.String(http.StatusInternalServerError, "Failed to load files")
-	}
-
-	if len(files) < 2 {
 		return c.String(http.StatusBadRequest, "Project must have both base and target files")
 	}
-
-	var baseFile, targetFile *map[string]interface{}
-	var baseLang, targetLang string
-
-	for i := range files {
-		var data map[string]interface{}
-		json.Unmarshal([]byte(files[i].Content), &data)
-
-		if files[i].FileType == "base" {
-			baseFile = &data
-			baseLang = files[i].LanguageCode
-		} else {
-			targetFile = &data
-			targetLang = files[i].LanguageCode
-		}
-	}
-
-	// Flatten both
-	baseFlat := jsontools.FlattenJSON(*baseFile, "")
-	targetFlat := jsontools.FlattenJSON(*targetFile, "")
+	baseFlat, targetFlat := data.BaseFlat, data.TargetFlat
+	baseLang, targetLang := data.BaseFile.LanguageCode, data.TargetFile.LanguageCode
 
 	// Compare
-	diff := jsontools.CompareJSON(baseFlat, targetFlat)
+	diff := data.diff()
 
 	// Prepare sorted keys for template
-	var sortedKeys []string
-	if viewMode == "missing" {
-		// Show only missing keys (sorted)
-		sortedKeys = diff.MissingKeys
-		sort.Strings(sortedKeys)
-	} else {
-		// Show all keys (sorted)
-		sortedKeys = make([]string, 0, len(baseFlat))
-		for key := range baseFlat {
-			sortedKeys = append(sortedKeys, key)
-		}
-		sort.Strings(sortedKeys)
-	}
+	sortedKeys := data.filterKeys(viewMode, statusFilter)
 
 	// Reconstruct JSON for raw view
 	nested := jsontools.UnflattenJSON(targetFlat)
@@ -99,10 +68,30 @@ func (h *EditorHandler) Editor(c echo.Context) error {
 	rawJSON := string(rawJSONBytes)
 
 	// Check if user is owner
-	sessionToken := session.GetSessionToken(c)
-	isOwner := sessionToken != "" && sessionToken == project.SessionToken
+	role := requestRole(c, h.db, project)
+	isOwner := role == models.RoleOwner
+
+	// Run QA checks for inline warnings
+	report := runQA(project, baseFlat, targetFlat, data.Meta, data.Terms)
+	issues := report.ByKey()
+	states := make(map[string]pages.FieldState, len(sortedKeys))
+	for _, key := range sortedKeys {
+		states[key] = data.fieldState(key, issues[key], role)
+	}
+
+	filters := pages.EditorFilters{
+		View:          viewMode,
+		Status:        statusFilter,
+		OutdatedCount: len(diff.OutdatedKeys),
+		QuestionCount: len(data.Questions),
+		LowCount:      len(data.lowConfidenceKeys()),
+		StatusCounts:  data.statusCounts(),
+	}
+
+	canEdit := role != models.RoleSuggester
+	canReview := isOwner || role == models.RoleReviewer
 
-	return render(c, pages.Editor(project, sortedKeys, baseFlat, targetFlat, rawJSON, baseLang, targetLang, viewMode == "missing", isOwner))
+	return render(c, pages.Editor(project, sortedKeys, baseFlat, targetFlat, states, rawJSON, baseLang, targetLang, filters, isOwner, canEdit, canReview))
 }
 
 // ProjectAuth handles GET /project/:id/auth - shows auth page for locked projects
@@ -162,7 +151,7 @@ func (h *EditorHandler) VerifyProjectKey(c echo.Context) error {
 }
 
 // isAuthenticated checks if the user is authenticated for a locked project
-func (h *EditorHandler) isAuthenticated(c echo.Context, project *models.Project) bool {
+func isAuthenticated(c echo.Context, project *models.Project) bool {
 	projectID := project.ID
 
 	// Check if user is owner (session match)
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/events"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment so
+// proxies do not close it
+const keepAliveInterval = 25 * time.Second
+
+type EventHandler struct {
+	db *database.DB
+}
+
+func NewEventHandler(db *database.DB) *EventHandler {
+	return &EventHandler{db: db}
+}
+
+// Stream handles GET /api/project/:id/events
+// Streams the project's live events as Server-Sent Events: "job" with the
+// state of a background job, "keys" with keys saved by anyone, and
+// "validation" with the QA results of saved keys or why a value was rejected.
+// Locked projects require the secret key or the owner's session.
+func (h *EventHandler) Stream(c echo.Context) error {
+	projectID := c.Param("id")
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	if project.IsLocked && !isAuthenticated(c, project) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Project is locked"})
+	}
+
+	ch, unsubscribe := events.Subscribe(projectID)
+	defer unsubscribe()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	w.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case ev := <-ch:
+			data, err := json.Marshal(ev.Data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+		}
+		w.Flush()
+	}
+}
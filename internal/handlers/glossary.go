@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/models"
+	"templui/internal/qa"
+	"templui/internal/session"
+	"templui/ui/pages"
+)
+
+type GlossaryHandler struct {
+	db *database.DB
+}
+
+func NewGlossaryHandler(db *database.DB) *GlossaryHandler {
+	return &GlossaryHandler{db: db}
+}
+
+// loadGlossary returns the terms that apply to a project: its own terms plus
+// those of linked organization glossaries. Project terms win over linked
+// terms with the same source text.
+func loadGlossary(db *database.DB, projectID string) ([]models.GlossaryTerm, error) {
+	var terms []models.GlossaryTerm
+	seen := make(map[string]bool)
+
+	own, err := db.GetProjectGlossary(projectID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if own != nil {
+		for _, t := range own.Terms {
+			seen[strings.ToLower(t.Term)] = true
+			terms = append(terms, t)
+		}
+	}
+
+	linked, err := db.GetLinkedGlossaries(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range linked {
+		for _, t := range g.Terms {
+			if !seen[strings.ToLower(t.Term)] {
+				seen[strings.ToLower(t.Term)] = true
+				terms = append(terms, t)
+			}
+		}
+	}
+	return terms, nil
+}
+
+// glossaryTerms converts glossary terms into QA terms for one target language
+func glossaryTerms(terms []models.GlossaryTerm, languageCode string) []qa.Term {
+	result := make([]qa.Term, 0, len(terms))
+	for _, t := range terms {
+		tr := t.Translations[languageCode]
+		result = append(result, qa.Term{
+			Source:         t.Term,
+			Translation:    tr.Translation,
+			Forbidden:      tr.Forbidden,
+			CaseSensitive:  t.CaseSensitive,
+			DoNotTranslate: t.DoNotTranslate,
+			Note:           t.Note,
+		})
+	}
+	return result
+}
+
+// TermRequest represents the request body for creating or replacing a term
+type TermRequest struct {
+	Term           string                            `json:"term"`
+	CaseSensitive  bool                              `json:"case_sensitive"`
+	DoNotTranslate bool                              `json:"do_not_translate"`
+	Note           string                            `json:"note"`
+	Translations   map[string]models.TermTranslation `json:"translations"` // By language code
+}
+
+// bindTerm reads a term from a JSON body, or from the editor form where the
+// translation and comma-separated forbidden variants apply to ?lang=
+func bindTerm(c echo.Context) (*TermRequest, error) {
+	var req TermRequest
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		if err := c.Bind(&req); err != nil {
+			return nil, err
+		}
+	} else {
+		req.Term = c.FormValue("term")
+		req.CaseSensitive = c.FormValue("case_sensitive") != ""
+		req.DoNotTranslate = c.FormValue("do_not_translate") != ""
+		req.Note = c.FormValue("note")
+		tr := models.TermTranslation{Translation: strings.TrimSpace(c.FormValue("translation"))}
+		for _, variant := range strings.Split(c.FormValue("forbidden"), ",") {
+			if variant = strings.TrimSpace(variant); variant != "" {
+				tr.Forbidden = append(tr.Forbidden, variant)
+			}
+		}
+		if lang := c.QueryParam("lang"); lang != "" && (tr.Translation != "" || len(tr.Forbidden) > 0) {
+			req.Translations = map[string]models.TermTranslation{lang: tr}
+		}
+	}
+	req.Term = strings.TrimSpace(req.Term)
+	req.Note = strings.TrimSpace(req.Note)
+	return &req, nil
+}
+
+// saveTerm creates a term in a glossary, or replaces it when termID is set
+func (h *GlossaryHandler) saveTerm(c echo.Context, glossary *models.Glossary, termID string) (*models.GlossaryTerm, int, string) {
+	req, err := bindTerm(c)
+	if err != nil {
+		return nil, http.StatusBadRequest, "Invalid request"
+	}
+	if req.Term == "" {
+		return nil, http.StatusBadRequest, "A term is required"
+	}
+	if req.Translations == nil {
+		req.Translations = map[string]models.TermTranslation{}
+	}
+
+	term := &models.GlossaryTerm{
+		ID:             generateID(),
+		GlossaryID:     glossary.ID,
+		Term:           req.Term,
+		CaseSensitive:  req.CaseSensitive,
+		DoNotTranslate: req.DoNotTranslate,
+		Note:           req.Note,
+		Translations:   req.Translations,
+		CreatedAt:      time.Now(),
+	}
+	status := http.StatusCreated
+	if termID != "" {
+		status = http.StatusOK
+		existing, err := h.db.GetGlossaryTerm(termID)
+		if err != nil || existing.GlossaryID != glossary.ID {
+			return nil, http.StatusNotFound, "Term not found"
+		}
+		term.ID, term.CreatedAt = existing.ID, existing.CreatedAt
+	}
+	for _, t := range glossary.Terms {
+		if t.ID != term.ID && strings.EqualFold(t.Term, term.Term) {
+			return nil, http.StatusConflict, "This term is already in the glossary"
+		}
+	}
+
+	if err := h.db.SaveGlossaryTerm(term); err != nil {
+		return nil, http.StatusInternalServerError, "Failed to save term"
+	}
+	return term, status, ""
+}
+
+// deleteTerm removes a term from a glossary
+func (h *GlossaryHandler) deleteTerm(glossary *models.Glossary, termID string) (int, string) {
+	term, err := h.db.GetGlossaryTerm(termID)
+	if err != nil || term.GlossaryID != glossary.ID {
+		return http.StatusNotFound, "Term not found"
+	}
+	if err := h.db.DeleteGlossaryTerm(term.ID); err != nil {
+		return http.StatusInternalServerError, "Failed to delete term"
+	}
+	return http.StatusOK, ""
+}
+
+// ownedProject loads a project and checks that the caller owns it
+func (h *GlossaryHandler) ownedProject(c echo.Context) (*models.Project, int, string) {
+	project, err := h.db.GetProject(c.Param("id"))
+	if err != nil {
+		return nil, http.StatusNotFound, "Project not found"
+	}
+	if project.SessionToken != session.GetSessionToken(c) {
+		return nil, http.StatusForbidden, "Unauthorized"
+	}
+	return project, http.StatusOK, ""
+}
+
+// projectGlossary returns the project's own glossary, creating it on first use
+func (h *GlossaryHandler) projectGlossary(project *models.Project) (*models.Glossary, error) {
+	glossary, err := h.db.GetProjectGlossary(project.ID)
+	if err == nil || !errors.Is(err, sql.ErrNoRows) {
+		return glossary, err
+	}
+	glossary = &models.Glossary{
+		ID:        generateID(),
+		Name:      project.Name,
+		ProjectID: project.ID,
+		CreatedAt: time.Now(),
+	}
+	if err := h.db.CreateGlossary(glossary); err != nil {
+		return nil, err
+	}
+	return glossary, nil
+}
+
+// renderGlossary renders the glossary dialog, or returns the project glossary
+// and linked glossaries as JSON
+func (h *GlossaryHandler) renderGlossary(c echo.Context, project *models.Project, status int, errorMessage string) error {
+	own, err := h.db.GetProjectGlossary(project.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get glossary"})
+	}
+	if own == nil {
+		own = &models.Glossary{Name: project.Name, ProjectID: project.ID, Terms: []models.GlossaryTerm{}}
+	}
+	linked, err := h.db.GetLinkedGlossaries(project.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get glossaries"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		isOwner := session.GetSessionToken(c) == project.SessionToken
+		return render(c, pages.GlossaryList(project.ID, c.QueryParam("lang"), own, linked, isOwner, errorMessage))
+	}
+	if errorMessage != "" {
+		return c.JSON(status, map[string]string{"error": errorMessage})
+	}
+	return c.JSON(status, map[string]interface{}{"glossary": own, "linked": linked})
+}
+
+// GetGlossary handles GET /api/project/:id/glossary
+func (h *GlossaryHandler) GetGlossary(c echo.Context) error {
+	project, err := h.db.GetProject(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	return h.renderGlossary(c, project, http.StatusOK, "")
+}
+
+// CreateProjectTerm handles POST /api/project/:id/glossary/terms
+func (h *GlossaryHandler) CreateProjectTerm(c echo.Context) error {
+	return h.saveProjectTerm(c, "")
+}
+
+// UpdateProjectTerm handles PUT /api/project/:id/glossary/terms/:tid
+func (h *GlossaryHandler) UpdateProjectTerm(c echo.Context) error {
+	return h.saveProjectTerm(c, c.Param("tid"))
+}
+
+func (h *GlossaryHandler) saveProjectTerm(c echo.Context, termID string) error {
+	project, status, message := h.ownedProject(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	glossary, err := h.projectGlossary(project)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get glossary"})
+	}
+
+	term, status, message := h.saveTerm(c, glossary, termID)
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return h.renderGlossary(c, project, status, message)
+	}
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	return c.JSON(status, term)
+}
+
+// DeleteProjectTerm handles DELETE /api/project/:id/glossary/terms/:tid
+func (h *GlossaryHandler) DeleteProjectTerm(c echo.Context) error {
+	project, status, message := h.ownedProject(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	glossary, err := h.projectGlossary(project)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get glossary"})
+	}
+
+	status, message = h.deleteTerm(glossary, c.Param("tid"))
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return h.renderGlossary(c, project, status, message)
+	}
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// LinkGlossary handles POST /api/project/:id/glossaries/:gid
+// Any organization glossary can be linked by its ID; only its owner can edit it.
+func (h *GlossaryHandler) LinkGlossary(c echo.Context) error {
+	project, status, message := h.ownedProject(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	gid := c.Param("gid")
+	if gid == "" {
+		gid = strings.TrimSpace(c.FormValue("glossary_id"))
+	}
+	glossary, err := h.db.GetGlossary(gid)
+	if err != nil || glossary.ProjectID != "" {
+		return h.renderGlossary(c, project, http.StatusNotFound, "Glossary not found")
+	}
+
+	if err := h.db.LinkGlossary(project.ID, glossary.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to link glossary"})
+	}
+	return h.renderGlossary(c, project, http.StatusOK, "")
+}
+
+// UnlinkGlossary handles DELETE /api/project/:id/glossaries/:gid
+func (h *GlossaryHandler) UnlinkGlossary(c echo.Context) error {
+	project, status, message := h.ownedProject(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if err := h.db.UnlinkGlossary(project.ID, c.Param("gid")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to unlink glossary"})
+	}
+	return h.renderGlossary(c, project, http.StatusOK, "")
+}
+
+// CreateGlossaryRequest represents the request body for an organization glossary
+type CreateGlossaryRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateGlossary handles POST /api/glossaries
+// Organization glossaries belong to the creating session and can be linked to
+// any number of projects.
+func (h *GlossaryHandler) CreateGlossary(c echo.Context) error {
+	var req CreateGlossaryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "A glossary name is required"})
+	}
+
+	glossary := &models.Glossary{
+		ID:           generateID(),
+		Name:         req.Name,
+		SessionToken: session.GetSessionToken(c),
+		CreatedAt:    time.Now(),
+		Terms:        []models.GlossaryTerm{},
+	}
+	if err := h.db.CreateGlossary(glossary); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create glossary"})
+	}
+	return c.JSON(http.StatusCreated, glossary)
+}
+
+// GetOrganizationGlossary handles GET /api/glossaries/:gid
+func (h *GlossaryHandler) GetOrganizationGlossary(c echo.Context) error {
+	glossary, err := h.db.GetGlossary(c.Param("gid"))
+	if err != nil || glossary.ProjectID != "" {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Glossary not found"})
+	}
+	return c.JSON(http.StatusOK, glossary)
+}
+
+// ownedGlossary loads an organization glossary and checks that the caller owns it
+func (h *GlossaryHandler) ownedGlossary(c echo.Context) (*models.Glossary, int, string) {
+	glossary, err := h.db.GetGlossary(c.Param("gid"))
+	if err != nil || glossary.ProjectID != "" {
+		return nil, http.StatusNotFound, "Glossary not found"
+	}
+	if glossary.SessionToken != session.GetSessionToken(c) {
+		return nil, http.StatusForbidden, "Unauthorized"
+	}
+	return glossary, http.StatusOK, ""
+}
+
+// CreateTerm handles POST /api/glossaries/:gid/terms
+func (h *GlossaryHandler) CreateTerm(c echo.Context) error {
+	return h.saveOrganizationTerm(c, "")
+}
+
+// UpdateTerm handles PUT /api/glossaries/:gid/terms/:tid
+func (h *GlossaryHandler) UpdateTerm(c echo.Context) error {
+	return h.saveOrganizationTerm(c, c.Param("tid"))
+}
+
+func (h *GlossaryHandler) saveOrganizationTerm(c echo.Context, termID string) error {
+	glossary, status, message := h.ownedGlossary(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	term, status, message := h.saveTerm(c, glossary, termID)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	return c.JSON(status, term)
+}
+
+// DeleteTerm handles DELETE /api/glossaries/:gid/terms/:tid
+func (h *GlossaryHandler) DeleteTerm(c echo.Context) error {
+	glossary, status, message := h.ownedGlossary(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if status, message := h.deleteTerm(glossary, c.Param("tid")); message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/events"
+	"templui/internal/jsontools"
+	"templui/internal/models"
+	"templui/internal/qa"
+	"templui/internal/session"
+	"templui/ui/pages"
+)
+
+// changeAuthor identifies who made a change
+type changeAuthor struct {
+	Type string
+	ID   string
+}
+
+// requestAuthor identifies the caller of a request. Requests carrying a valid
+// API key for the project are attributed to that key, everything else to the
+// session. Session tokens are fingerprinted so they are never stored in history.
+func requestAuthor(c echo.Context, db *database.DB, projectID string) changeAuthor {
+	if apiKey := requestAPIKey(c, db, projectID); apiKey != nil {
+		return changeAuthor{Type: models.AuthorAPIKey, ID: apiKey.ID}
+	}
+
+	return changeAuthor{Type: models.AuthorSession, ID: sessionFingerprint(c)}
+}
+
+// sessionFingerprint identifies the browser session without exposing its token
+func sessionFingerprint(c echo.Context) string {
+	hash := sha256.Sum256([]byte(session.GetSessionToken(c)))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+// buildRevisions describes the changes between two versions of a flattened file.
+// base holds the source values the new values were translated from.
+func buildRevisions(file *models.TranslationFile, base, before, after map[string]string, author changeAuthor, source string) []models.Revision {
+	batchID := generateID()
+	now := time.Now()
+
+	keys := make([]string, 0, len(after))
+	for key := range after {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var revisions []models.Revision
+	for _, key := range keys {
+		if before[key] == after[key] {
+			continue
+		}
+		var sourceHash string
+		if baseValue, ok := base[key]; ok && after[key] != "" {
+			sourceHash = jsontools.SourceHash(baseValue)
+		}
+		revisions = append(revisions, models.Revision{
+			ID:           generateID(),
+			ProjectID:    file.ProjectID,
+			FileID:       file.ID,
+			LanguageCode: file.LanguageCode,
+			Key:          key,
+			OldValue:     before[key],
+			NewValue:     after[key],
+			AuthorType:   author.Type,
+			AuthorID:     author.ID,
+			Source:       source,
+			BatchID:      batchID,
+			SourceHash:   sourceHash,
+			CreatedAt:    now,
+		})
+	}
+	return revisions
+}
+
+// saveTarget writes the updated flattened values to the file and records a
+// revision for every changed key
+func saveTarget(db *database.DB, file *models.TranslationFile, base, before, after map[string]string, author changeAuthor, source string) ([]models.Revision, error) {
+	return saveTargetInBatch(db, file, base, before, after, author, source, "", nil)
+}
+
+// saveTargetInBatch is saveTarget for operations that save in several steps.
+// The revisions are recorded under batchID so they can be undone together.
+// promptIDs links AI translations to the recorded prompt that produced them.
+func saveTargetInBatch(db *database.DB, file *models.TranslationFile, base, before, after map[string]string, author changeAuthor, source, batchID string, promptIDs map[string]string) ([]models.Revision, error) {
+	revisions := buildRevisions(file, base, before, after, author, source)
+	for i := range revisions {
+		if batchID != "" {
+			revisions[i].BatchID = batchID
+		}
+		revisions[i].PromptID = promptIDs[revisions[i].Key]
+	}
+	return revisions, writeRevisions(db, file, base, after, revisions, author, source, batchID)
+}
+
+// saveReverted is saveTarget for restored values. They keep the source hash
+// of the revision they come from instead of the current base value's, so a
+// value restored from before a source change is still outdated.
+func saveReverted(db *database.DB, file *models.TranslationFile, base, before, after, sources map[string]string, author changeAuthor) ([]models.Revision, error) {
+	revisions := buildRevisions(file, base, before, after, author, models.SourceRevert)
+	for i := range revisions {
+		if revisions[i].NewValue != "" {
+			revisions[i].SourceHash = sources[revisions[i].Key]
+		}
+	}
+	return revisions, writeRevisions(db, file, base, after, revisions, author, models.SourceRevert, "")
+}
+
+// writeRevisions saves the file and its revisions and tells open editors
+func writeRevisions(db *database.DB, file *models.TranslationFile, base, after map[string]string, revisions []models.Revision, author changeAuthor, source, batchID string) error {
+	updatedJSON, err := json.MarshalIndent(jsontools.UnflattenJSON(after), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := db.UpdateFileWithRevisions(file.ID, string(updatedJSON), revisions); err != nil {
+		return err
+	}
+	file.Content = string(updatedJSON)
+	rememberTranslations(db, file, base, revisions)
+	publishKeys(file, revisions, author, source, batchID)
+	return nil
+}
+
+// restoredSource returns the source hash of a value restored from a revision:
+// the revision's own for its new value, or that of the revision before it for
+// the value it replaced. It is "" when the source is unknown.
+func restoredSource(db *database.DB, rev *models.Revision, toBefore bool) string {
+	if !toBefore {
+		return rev.SourceHash
+	}
+	prev, err := db.GetPreviousRevision(rev)
+	if err != nil || prev == nil || prev.NewValue != rev.OldValue {
+		return ""
+	}
+	return prev.SourceHash
+}
+
+// publishKeys tells open editors which keys of a file were saved
+func publishKeys(file *models.TranslationFile, revisions []models.Revision, author changeAuthor, source, batchID string) {
+	if len(revisions) == 0 {
+		return
+	}
+	keys := make([]string, len(revisions))
+	for i, rev := range revisions {
+		keys[i] = rev.Key
+	}
+	events.Publish(file.ProjectID, events.Event{Type: events.TypeKeys, Data: events.KeysEvent{
+		Language:   file.LanguageCode,
+		Keys:       keys,
+		AuthorType: author.Type,
+		AuthorID:   author.ID,
+		Source:     source,
+		BatchID:    batchID,
+	}})
+}
+
+type HistoryHandler struct {
+	db *database.DB
+}
+
+func NewHistoryHandler(db *database.DB) *HistoryHandler {
+	return &HistoryHandler{db: db}
+}
+
+// KeyHistory handles GET /api/project/:id/history?key=
+func (h *HistoryHandler) KeyHistory(c echo.Context) error {
+	projectID := c.Param("id")
+	key := c.QueryParam("key")
+
+	data, err := loadProjectData(h.db, projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	revisions, err := h.db.GetKeyRevisions(data.TargetFile.ID, key, 50)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get history"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return render(c, pages.KeyHistory(projectID, key, revisions))
+	}
+	return c.JSON(http.StatusOK, revisions)
+}
+
+// Prompt handles GET /api/project/:id/prompts/:pid
+// Returns the prompt an AI translation was made with, as plain text with ?format=text
+func (h *HistoryHandler) Prompt(c echo.Context) error {
+	prompt, err := h.db.GetPrompt(c.Param("id"), c.Param("pid"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Prompt not found"})
+	}
+	if c.QueryParam("format") == "text" {
+		return c.String(http.StatusOK, prompt.Prompt)
+	}
+	return c.JSON(http.StatusOK, prompt)
+}
+
+// Batches handles GET /api/project/:id/batches?source=ai
+func (h *HistoryHandler) Batches(c echo.Context) error {
+	batches, err := h.db.GetRevisionBatches(c.Param("id"), c.QueryParam("source"), 50)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get batches"})
+	}
+	return c.JSON(http.StatusOK, batches)
+}
+
+// RevertRevision handles POST /api/project/:id/history/:rid/revert
+// The key is restored to the value the revision produced, or with ?to=before
+// to the value it replaced.
+func (h *HistoryHandler) RevertRevision(c echo.Context) error {
+	projectID := c.Param("id")
+
+	data, err := loadProjectData(h.db, projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if !requireEditor(c, h.db, data.Project) {
+		return nil
+	}
+
+	rev, err := h.db.GetRevision(c.Param("rid"))
+	if err != nil || rev.ProjectID != projectID || rev.FileID != data.TargetFile.ID {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Revision not found"})
+	}
+
+	value := rev.NewValue
+	if c.QueryParam("to") == "before" {
+		value = rev.OldValue
+	}
+	if value != data.TargetFlat[rev.Key] && data.locked(rev.Key, requestRole(c, h.db, data.Project)) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "The value is approved and can only be changed by owners and reviewers"})
+	}
+
+	// Restored values get the same checks as a save
+	if value != "" {
+		if _, failedKey, message := checkTranslations(data, map[string]string{rev.Key: value}); failedKey != "" {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Cannot restore value: " + message})
+		}
+	}
+
+	before := maps.Clone(data.TargetFlat)
+	data.TargetFlat[rev.Key] = value
+	sources := map[string]string{rev.Key: restoredSource(h.db, rev, c.QueryParam("to") == "before")}
+	if _, err := saveReverted(h.db, data.TargetFile, data.BaseFlat, before, data.TargetFlat, sources, requestAuthor(c, h.db, projectID)); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update file"})
+	}
+	data.Sources[rev.Key] = sources[rev.Key]
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return renderField(c, h.db, data, rev.Key, "")
+	}
+	return c.JSON(http.StatusOK, map[string]string{"key": rev.Key, "value": value})
+}
+
+// RevertBatch handles POST /api/project/:id/batches/:bid/revert
+// Keys changed by the batch are restored to their previous value unless they
+// were edited again afterwards; those are reported as conflicts.
+func (h *HistoryHandler) RevertBatch(c echo.Context) error {
+	projectID := c.Param("id")
+
+	data, err := loadProjectData(h.db, projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if !requireEditor(c, h.db, data.Project) {
+		return nil
+	}
+
+	revisions, err := h.db.GetBatchRevisions(c.Param("bid"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get batch"})
+	}
+	if len(revisions) == 0 || revisions[0].ProjectID != projectID {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Batch not found"})
+	}
+
+	role := requestRole(c, h.db, data.Project)
+	before := maps.Clone(data.TargetFlat)
+	reverted := []string{}
+	conflicts := []string{}
+	locked := []string{}
+	for _, rev := range revisions {
+		if rev.FileID != data.TargetFile.ID {
+			continue
+		}
+		if data.TargetFlat[rev.Key] != rev.NewValue {
+			conflicts = append(conflicts, rev.Key)
+			continue
+		}
+		if data.locked(rev.Key, role) {
+			locked = append(locked, rev.Key)
+			continue
+		}
+		data.TargetFlat[rev.Key] = rev.OldValue
+		reverted = append(reverted, rev.Key)
+	}
+
+	// Restored values get the same checks as a save; keys that fail keep their value
+	invalid := make(map[string]string)
+	issues := runQAForKeys(data, reverted).ByKey()
+	reverted = slices.DeleteFunc(reverted, func(key string) bool {
+		value := data.TargetFlat[key]
+		if value == "" {
+			return false
+		}
+		problem := ""
+		if err := jsontools.ValidateValue(data.BaseFlat[key], value); err != nil {
+			problem = err.Error()
+		}
+		for _, issue := range issues[key] {
+			if problem == "" && issue.Severity == qa.SeverityError {
+				problem = issue.Message
+			}
+		}
+		if problem == "" {
+			return false
+		}
+		invalid[key] = problem
+		data.TargetFlat[key] = before[key]
+		return true
+	})
+
+	if len(reverted) > 0 {
+		sources := make(map[string]string, len(reverted))
+		for _, rev := range revisions {
+			if slices.Contains(reverted, rev.Key) {
+				sources[rev.Key] = restoredSource(h.db, &rev, true)
+			}
+		}
+		if _, err := saveReverted(h.db, data.TargetFile, data.BaseFlat, before, data.TargetFlat, sources, requestAuthor(c, h.db, projectID)); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update file"})
+		}
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		c.Response().Header().Set("HX-Refresh", "true")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"reverted":  reverted,
+		"conflicts": conflicts,
+		"locked":    locked,
+		"invalid":   invalid,
+	})
+}
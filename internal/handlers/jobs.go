@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/events"
+	"templui/internal/jobs"
+	"templui/internal/models"
+	"templui/internal/session"
+	"templui/ui/pages"
+)
+
+// RegisterJobs registers the handlers of all background job types
+func RegisterJobs(queue *jobs.Queue, db *database.DB) {
+	queue.Register(models.JobAutoTranslate, func(ctx context.Context, job *models.Job, progress jobs.Progress) (interface{}, error) {
+		return autoTranslate(ctx, db, job, progress)
+	})
+	queue.Register(models.JobTMXImport, func(ctx context.Context, job *models.Job, progress jobs.Progress) (interface{}, error) {
+		return importTMX(ctx, db, job, progress)
+	})
+	// Imported files can be up to 50 MB
+	queue.Transient(models.JobTMXImport, "content")
+	queue.Register(models.JobQAReport, func(ctx context.Context, job *models.Job, progress jobs.Progress) (interface{}, error) {
+		return qaReport(db, job)
+	})
+	queue.Register(models.JobQualityReview, func(ctx context.Context, job *models.Job, progress jobs.Progress) (interface{}, error) {
+		return qualityReview(ctx, db, job, progress)
+	})
+}
+
+// renderJob returns a job as JSON, or as the polling progress fragment for the editor
+func renderJob(c echo.Context, job *models.Job, status int) error {
+	if c.Request().Header.Get("HX-Request") == "true" {
+		c.Response().WriteHeader(status)
+		return render(c, pages.JobStatus(job))
+	}
+	return c.JSON(status, job)
+}
+
+type JobHandler struct {
+	db *database.DB
+}
+
+func NewJobHandler(db *database.DB) *JobHandler {
+	return &JobHandler{db: db}
+}
+
+// loadJob loads a job the caller may see. Project jobs are visible to anyone
+// with the job ID, like the project itself; other jobs only to their owner.
+func (h *JobHandler) loadJob(c echo.Context) (*models.Job, bool) {
+	job, err := h.db.GetJob(c.Param("jid"))
+	if err != nil {
+		return nil, false
+	}
+	if job.ProjectID == "" && job.Owner != models.OwnerID(session.GetSessionToken(c)) {
+		return nil, false
+	}
+	return job, true
+}
+
+// GetJob handles GET /api/jobs/:jid
+func (h *JobHandler) GetJob(c echo.Context) error {
+	job, ok := h.loadJob(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Job not found"})
+	}
+	return renderJob(c, job, http.StatusOK)
+}
+
+// CancelJob handles POST /api/jobs/:jid/cancel
+// Queued jobs are cancelled right away, running jobs stop after their current
+// step and keep the progress saved so far.
+func (h *JobHandler) CancelJob(c echo.Context) error {
+	job, ok := h.loadJob(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Job not found"})
+	}
+
+	allowed := job.Owner != "" && job.Owner == models.OwnerID(session.GetSessionToken(c))
+	if !allowed && job.ProjectID != "" {
+		if project, err := h.db.GetProject(job.ProjectID); err == nil && requestRole(c, h.db, project) == models.RoleOwner {
+			allowed = true
+		} else if apiKey := requestAPIKey(c, h.db, job.ProjectID); apiKey != nil && apiKey.HasPermission("write") {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only the project owner or whoever started the job can cancel it"})
+	}
+
+	if _, err := h.db.CancelJob(job.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to cancel job"})
+	}
+	job, err := h.db.GetJob(job.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get job"})
+	}
+	if job.ProjectID != "" {
+		events.Publish(job.ProjectID, events.Event{Type: events.TypeJob, Data: events.NewJobEvent(job)})
+	}
+	return renderJob(c, job, http.StatusOK)
+}
+
+// ListProjectJobs handles GET /api/project/:id/jobs?active=true
+func (h *JobHandler) ListProjectJobs(c echo.Context) error {
+	var statuses []string
+	if c.QueryParam("active") == "true" {
+		statuses = []string{models.JobQueued, models.JobRunning}
+	}
+	list, err := h.db.GetProjectJobs(c.Param("id"), statuses, 20)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get jobs"})
+	}
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return render(c, pages.JobList(list))
+	}
+	return c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,632 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+
+	"templui/internal/database"
+	"templui/internal/events"
+	"templui/internal/jobs"
+	"templui/internal/models"
+	"templui/internal/session"
+	"templui/internal/tmx"
+	"templui/ui/pages"
+)
+
+// MemoryMinScore is the default similarity in percent for fuzzy memory matches
+const MemoryMinScore = 75
+
+// maxTMXSize limits the size of imported TMX files
+const maxTMXSize = 50 << 20
+
+type MemoryHandler struct {
+	db    *database.DB
+	queue *jobs.Queue
+}
+
+func NewMemoryHandler(db *database.DB, queue *jobs.Queue) *MemoryHandler {
+	return &MemoryHandler{db: db, queue: queue}
+}
+
+// memorySources are the revision sources whose values were entered by people.
+// Machine translations, memory fills and restored values only go into the
+// translation memory once they are approved.
+var memorySources = map[string]bool{
+	models.SourceManual:     true,
+	models.SourceImport:     true,
+	models.SourceSuggestion: true,
+}
+
+// rememberTranslations adds the human translated values of saved revisions to
+// the translation memory of the project owner. Failures are logged, not
+// returned, since the translations themselves were saved.
+func rememberTranslations(db *database.DB, file *models.TranslationFile, base map[string]string, revisions []models.Revision) {
+	values := make(map[string]string)
+	origins := make(map[string]string)
+	for _, r := range revisions {
+		if memorySources[r.Source] {
+			values[r.Key] = r.NewValue
+			origins[r.Key] = r.Source
+		}
+	}
+	remember(db, file, base, values, origins)
+}
+
+// rememberApproved adds an approved value to the translation memory of the
+// project owner, whoever or whatever produced it
+func rememberApproved(db *database.DB, data *projectData, key string) {
+	values := map[string]string{key: data.TargetFlat[key]}
+	remember(db, data.TargetFile, data.BaseFlat, values, map[string]string{key: models.StatusApproved})
+}
+
+// remember stores the translated values of a file by key in the translation
+// memory of the project owner, with the origin of each
+func remember(db *database.DB, file *models.TranslationFile, base, values, origins map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	project, err := db.GetProject(file.ProjectID)
+	if err != nil {
+		log.Errorf("Failed to load project for translation memory: %v", err)
+		return
+	}
+	owner := models.OwnerID(project.SessionToken)
+	if owner == "" {
+		return
+	}
+	files, err := db.GetFilesByProject(file.ProjectID)
+	if err != nil {
+		log.Errorf("Failed to load files for translation memory: %v", err)
+		return
+	}
+	baseFile, _ := findProjectFiles(files)
+	if baseFile == nil {
+		return
+	}
+
+	now := time.Now()
+	entries := make([]models.MemoryEntry, 0, len(values))
+	for key, value := range values {
+		if value == "" || base[key] == "" {
+			continue
+		}
+		entries = append(entries, models.MemoryEntry{
+			ID:         generateID(),
+			Owner:      owner,
+			SourceLang: baseFile.LanguageCode,
+			TargetLang: file.LanguageCode,
+			SourceText: base[key],
+			TargetText: value,
+			ProjectID:  file.ProjectID,
+			Origin:     origins[key],
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+	if err := db.AddMemoryEntries(entries); err != nil {
+		log.Errorf("Failed to update translation memory: %v", err)
+	}
+}
+
+// memoryOwners returns the memories a project reads from: its owner's and the
+// linked organization memories
+func memoryOwners(db *database.DB, project *models.Project) ([]string, error) {
+	var owners []string
+	if owner := models.OwnerID(project.SessionToken); owner != "" {
+		owners = append(owners, owner)
+	}
+	linked, err := db.GetLinkedMemories(project.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range linked {
+		owners = append(owners, models.MemoryOwnerID(m.ID))
+	}
+	return owners, nil
+}
+
+// fillFromMemory fills empty target values with exact translation memory
+// matches and saves them as one batch. Keys in skip are left alone. Matches go
+// through the same checks as machine translations: values that break
+// placeholders or markup are not used, values with other problems are saved
+// with the needs review status and returned with the problem.
+func fillFromMemory(db *database.DB, data *projectData, skip map[string]bool, author changeAuthor) ([]models.Revision, map[string]string, error) {
+	owners, err := memoryOwners(db, data.Project)
+	if err != nil || len(owners) == 0 {
+		return nil, nil, err
+	}
+	baseFlat, targetFlat := data.BaseFlat, data.TargetFlat
+
+	texts := []string{}
+	for key, value := range baseFlat {
+		if value != "" && targetFlat[key] == "" && !skip[key] {
+			texts = append(texts, value)
+		}
+	}
+	if len(texts) == 0 {
+		return nil, nil, nil
+	}
+	matches, err := db.FindExactMemory(owners, data.BaseFile.LanguageCode, data.TargetFile.LanguageCode, texts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := newMTValidator(data)
+	review := make(map[string]string)
+	before := maps.Clone(targetFlat)
+	for key, value := range baseFlat {
+		match, ok := matches[value]
+		if !ok || targetFlat[key] != "" || skip[key] {
+			continue
+		}
+		problem, fatal := v.check(key, match.TargetText)
+		if fatal {
+			continue
+		}
+		if problem != "" {
+			review[key] = problem
+		}
+		targetFlat[key] = match.TargetText
+	}
+	if maps.Equal(before, targetFlat) {
+		return nil, nil, nil
+	}
+	revisions, err := saveTarget(db, data.TargetFile, baseFlat, before, targetFlat, author, models.SourceMemory)
+	if err != nil {
+		return nil, nil, err
+	}
+	for key, problem := range review {
+		if err := db.SetStatus(data.TargetFile.ID, key, models.StatusNeedsReview, author.ID); err != nil {
+			return nil, nil, err
+		}
+		events.Publish(data.Project.ID, events.Event{Type: events.TypeValidation, Data: events.ValidationEvent{Key: key, Error: problem}})
+	}
+	return revisions, review, nil
+}
+
+// Lookup handles GET /api/project/:id/memory?key=
+// Returns exact and fuzzy matches for the base value of the key. ?min= sets
+// the minimum similarity in percent.
+func (h *MemoryHandler) Lookup(c echo.Context) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	key := c.QueryParam("key")
+	baseValue, ok := data.BaseFlat[key]
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Key not found"})
+	}
+
+	minScore := MemoryMinScore
+	if n, err := strconv.Atoi(c.QueryParam("min")); err == nil && n > 0 && n <= 100 {
+		minScore = n
+	}
+
+	owners, err := memoryOwners(h.db, data.Project)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search translation memory"})
+	}
+	matches := []models.MemoryMatch{}
+	if len(owners) > 0 {
+		matches, err = h.db.FindMemoryMatches(owners, data.BaseFile.LanguageCode, data.TargetFile.LanguageCode, baseValue, minScore, 10)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search translation memory"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		canApply := requestRole(c, h.db, data.Project) != models.RoleSuggester
+		return render(c, pages.MemoryMatches(data.Project.ID, key, matches, canApply))
+	}
+	return c.JSON(http.StatusOK, matches)
+}
+
+// Apply handles POST /api/project/:id/memory/:mid/apply?key=
+// The memory translation goes through the same validation as a direct edit.
+func (h *MemoryHandler) Apply(c echo.Context) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if !requireEditor(c, h.db, data.Project) {
+		return nil
+	}
+	key := c.QueryParam("key")
+	if _, ok := data.BaseFlat[key]; !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Key not found"})
+	}
+	owners, err := memoryOwners(h.db, data.Project)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get translation memory"})
+	}
+	var entry *models.MemoryEntry
+	if len(owners) > 0 {
+		entry, err = h.db.GetMemoryEntry(owners, c.Param("mid"))
+	}
+	if entry == nil || err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Memory entry not found"})
+	}
+
+	author := requestAuthor(c, h.db, data.Project.ID)
+	values := map[string]string{key: entry.TargetText}
+	_, failedKey, message, err := applyTranslations(h.db, data, values, author, models.SourceMemory)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update file"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return renderField(c, h.db, data, key, message)
+	}
+	if failedKey != "" {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": message})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"key": key, "value": entry.TargetText})
+}
+
+// Pretranslate handles POST /api/project/:id/pretranslate
+// Fills every missing translation that has a 100% memory match.
+func (h *MemoryHandler) Pretranslate(c echo.Context) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if !requireEditor(c, h.db, data.Project) {
+		return nil
+	}
+
+	skip := make(map[string]bool)
+	for key, m := range data.Meta {
+		if m.DoNotTranslate {
+			skip[key] = true
+		}
+	}
+
+	author := requestAuthor(c, h.db, data.Project.ID)
+	revisions, review, err := fillFromMemory(h.db, data, skip, author)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to pre-translate"})
+	}
+	batchID := ""
+	if len(revisions) > 0 {
+		batchID = revisions[0].BatchID
+	}
+
+	c.Response().Header().Set("HX-Refresh", "true")
+	return c.JSON(http.StatusOK, map[string]interface{}{"filled": len(revisions), "needs_review": review, "batch_id": batchID})
+}
+
+// ImportTMX handles POST /api/memory/import
+// Queues the import of a TMX file (form field "file" or the raw body) into the
+// caller's translation memory, or with ?memory= into an organization memory
+// the caller owns. Language codes are normalized to BCP 47, and with
+// ?project= mapped onto that project's language tags. The source language is
+// taken from ?source=, the project's base language or the TMX header.
+func (h *MemoryHandler) ImportTMX(c echo.Context) error {
+	owner := models.OwnerID(session.GetSessionToken(c))
+	if owner == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "No session"})
+	}
+
+	params := tmxImportParams{Owner: owner, SourceLang: c.QueryParam("source")}
+	if memoryID := c.QueryParam("memory"); memoryID != "" {
+		memory, status, message := h.ownedMemory(c, memoryID)
+		if message != "" {
+			return c.JSON(status, map[string]string{"error": message})
+		}
+		params.Owner = models.MemoryOwnerID(memory.ID)
+	}
+	if projectID := c.QueryParam("project"); projectID != "" {
+		project, err := h.db.GetProject(projectID)
+		if err != nil || models.OwnerID(project.SessionToken) != owner {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+		}
+		files, err := h.db.GetFilesByProject(projectID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get files"})
+		}
+		for _, f := range files {
+			params.Tags = append(params.Tags, f.LanguageCode)
+			if f.FileType == "base" && params.SourceLang == "" {
+				params.SourceLang = f.LanguageCode
+			}
+		}
+	}
+
+	var body io.Reader = c.Request().Body
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		src, err := fileHeader.Open()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read file"})
+		}
+		defer src.Close()
+		body = src
+	}
+	content, err := io.ReadAll(io.LimitReader(body, maxTMXSize+1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read file"})
+	}
+	if len(content) > maxTMXSize {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "TMX file is larger than 50 MB"})
+	}
+	params.Content = string(content)
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to queue import"})
+	}
+	job := &models.Job{
+		ID:        generateID(),
+		Type:      models.JobTMXImport,
+		ProjectID: c.QueryParam("project"),
+		Owner:     owner,
+		Params:    data,
+	}
+	if err := h.queue.Enqueue(job); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to queue import"})
+	}
+	return renderJob(c, job, http.StatusAccepted)
+}
+
+// tmxImportParams are the parameters of a tmx_import job
+type tmxImportParams struct {
+	Owner      string   `json:"owner"` // Memory to import into, see MemoryEntry.Owner
+	SourceLang string   `json:"source_lang,omitempty"`
+	Tags       []string `json:"tags,omitempty"` // Project language tags to map onto
+	Content    string   `json:"content"`
+}
+
+// tmxImportResult is the outcome of a tmx_import job
+type tmxImportResult struct {
+	Imported    int      `json:"imported"`
+	Skipped     int      `json:"skipped"`
+	SourceLang  string   `json:"source_lang"`
+	TargetLangs []string `json:"target_langs"`
+}
+
+// tmxImportChunk is the number of memory entries stored per progress update
+const tmxImportChunk = 500
+
+// importTMX parses a TMX file and adds its translation units to the memory of params.Owner
+func importTMX(ctx context.Context, db *database.DB, job *models.Job, progress jobs.Progress) (interface{}, error) {
+	var params tmxImportParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, err
+	}
+
+	doc, err := tmx.Parse(strings.NewReader(params.Content))
+	if err != nil {
+		return nil, err
+	}
+	sourceLang := params.SourceLang
+	if sourceLang == "" {
+		sourceLang = doc.SourceLang
+	}
+	if sourceLang == "" || sourceLang == "*all*" {
+		return nil, fmt.Errorf("the TMX file has no source language, pass ?source=")
+	}
+	sourceLang = tmx.MatchLanguage(sourceLang, params.Tags)
+
+	now := time.Now()
+	var entries []models.MemoryEntry
+	result := &tmxImportResult{SourceLang: sourceLang, TargetLangs: []string{}}
+	languages := make(map[string]bool)
+	for _, unit := range doc.Units {
+		// Map every segment onto a project tag, keeping the first variant per tag
+		segments := make(map[string]string)
+		for lang, text := range unit.Segments {
+			lang = tmx.MatchLanguage(lang, params.Tags)
+			if _, ok := segments[lang]; !ok && strings.TrimSpace(text) != "" {
+				segments[lang] = text
+			}
+		}
+		source, ok := segments[sourceLang]
+		if !ok || len(segments) < 2 {
+			result.Skipped++
+			continue
+		}
+		for lang, text := range segments {
+			if lang == sourceLang {
+				continue
+			}
+			if !languages[lang] {
+				languages[lang] = true
+				result.TargetLangs = append(result.TargetLangs, lang)
+			}
+			entries = append(entries, models.MemoryEntry{
+				ID:         generateID(),
+				Owner:      params.Owner,
+				SourceLang: sourceLang,
+				TargetLang: lang,
+				SourceText: source,
+				TargetText: text,
+				Origin:     "tmx",
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			})
+		}
+	}
+
+	for start := 0; start < len(entries); start += tmxImportChunk {
+		if err := ctx.Err(); err != nil {
+			return result, nil
+		}
+		chunk := entries[start:min(start+tmxImportChunk, len(entries))]
+		if err := db.AddMemoryEntries(chunk); err != nil {
+			return nil, fmt.Errorf("failed to import translation memory: %w", err)
+		}
+		result.Imported += len(chunk)
+		progress(result.Imported, len(entries))
+	}
+	sort.Strings(result.TargetLangs)
+	return result, nil
+}
+
+// ExportTMX handles GET /api/memory/export
+// Exports the caller's translation memory as TMX, or with ?memory= an
+// organization memory, optionally limited with ?source= and ?target=.
+func (h *MemoryHandler) ExportTMX(c echo.Context) error {
+	owner := models.OwnerID(session.GetSessionToken(c))
+	if memoryID := c.QueryParam("memory"); memoryID != "" {
+		memory, err := h.db.GetMemory(memoryID)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Memory not found"})
+		}
+		owner = models.MemoryOwnerID(memory.ID)
+	}
+	if owner == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "No session"})
+	}
+
+	sourceLang, targetLang := c.QueryParam("source"), c.QueryParam("target")
+	entries, err := h.db.ListMemoryEntries(owner, sourceLang, targetLang)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get translation memory"})
+	}
+
+	// One unit per source text, with the most used translation per language
+	var units []tmx.Unit
+	index := make(map[string]int)
+	for _, e := range entries {
+		id := e.SourceLang + "\x00" + e.SourceText
+		i, ok := index[id]
+		if !ok {
+			i = len(units)
+			index[id] = i
+			units = append(units, tmx.Unit{Segments: map[string]string{e.SourceLang: e.SourceText}})
+		}
+		if _, ok := units[i].Segments[e.TargetLang]; !ok {
+			units[i].Segments[e.TargetLang] = e.TargetText
+		}
+	}
+
+	headerLang := sourceLang
+	if headerLang == "" {
+		headerLang = "*all*"
+	}
+	c.Response().Header().Set(echo.HeaderContentType, "application/x-tmx+xml")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="memory.tmx"`)
+	c.Response().WriteHeader(http.StatusOK)
+	return tmx.Write(c.Response(), headerLang, units)
+}
+
+// CreateMemoryRequest represents the request body for an organization memory
+type CreateMemoryRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateMemory handles POST /api/memories
+// Organization memories belong to the creating session, which fills them by
+// TMX import, and can be linked to any number of projects.
+func (h *MemoryHandler) CreateMemory(c echo.Context) error {
+	var req CreateMemoryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "A memory name is required"})
+	}
+	token := session.GetSessionToken(c)
+	if token == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "No session"})
+	}
+
+	memory := &models.Memory{
+		ID:           generateID(),
+		Name:         req.Name,
+		SessionToken: token,
+		CreatedAt:    time.Now(),
+	}
+	if err := h.db.CreateMemory(memory); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create memory"})
+	}
+	return c.JSON(http.StatusCreated, memory)
+}
+
+// GetOrganizationMemory handles GET /api/memories/:mid
+func (h *MemoryHandler) GetOrganizationMemory(c echo.Context) error {
+	memory, err := h.db.GetMemory(c.Param("mid"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Memory not found"})
+	}
+	return c.JSON(http.StatusOK, memory)
+}
+
+// ownedMemory loads an organization memory and checks that the caller owns it
+func (h *MemoryHandler) ownedMemory(c echo.Context, memoryID string) (*models.Memory, int, string) {
+	memory, err := h.db.GetMemory(memoryID)
+	if err != nil {
+		return nil, http.StatusNotFound, "Memory not found"
+	}
+	if memory.SessionToken != session.GetSessionToken(c) {
+		return nil, http.StatusForbidden, "Unauthorized"
+	}
+	return memory, http.StatusOK, ""
+}
+
+// ownedProject loads the project and checks that the caller owns it
+func (h *MemoryHandler) ownedProject(c echo.Context) (*models.Project, int, string) {
+	project, err := h.db.GetProject(c.Param("id"))
+	if err != nil {
+		return nil, http.StatusNotFound, "Project not found"
+	}
+	if project.SessionToken != session.GetSessionToken(c) {
+		return nil, http.StatusForbidden, "Unauthorized"
+	}
+	return project, http.StatusOK, ""
+}
+
+// LinkedMemories handles GET /api/project/:id/memories
+func (h *MemoryHandler) LinkedMemories(c echo.Context) error {
+	project, status, message := h.ownedProject(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	memories, err := h.db.GetLinkedMemories(project.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get memories"})
+	}
+	return c.JSON(http.StatusOK, memories)
+}
+
+// LinkMemory handles POST /api/project/:id/memories/:mid
+// Any organization memory can be linked by its ID; the project then finds
+// matches in it too. Only its owner can import into it.
+func (h *MemoryHandler) LinkMemory(c echo.Context) error {
+	project, status, message := h.ownedProject(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	memory, err := h.db.GetMemory(c.Param("mid"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Memory not found"})
+	}
+	if err := h.db.LinkMemory(project.ID, memory.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to link memory"})
+	}
+	return h.LinkedMemories(c)
+}
+
+// UnlinkMemory handles DELETE /api/project/:id/memories/:mid
+func (h *MemoryHandler) UnlinkMemory(c echo.Context) error {
+	project, status, message := h.ownedProject(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if err := h.db.UnlinkMemory(project.ID, c.Param("mid")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to unlink memory"})
+	}
+	return h.LinkedMemories(c)
+}
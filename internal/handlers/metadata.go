@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/jsontools"
+	"templui/internal/models"
+	"templui/internal/session"
+)
+
+type MetadataHandler struct {
+	db *database.DB
+}
+
+func NewMetadataHandler(db *database.DB) *MetadataHandler {
+	return &MetadataHandler{db: db}
+}
+
+// GetMetadata handles GET /api/project/:id/metadata
+func (h *MetadataHandler) GetMetadata(c echo.Context) error {
+	projectID := c.Param("id")
+
+	meta, err := h.db.GetKeyMetadataByProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get metadata"})
+	}
+
+	return c.JSON(http.StatusOK, meta)
+}
+
+// metadataTags are the tags of an UpdateMetadataRequest: a JSON array in JSON
+// bodies, or a comma-separated list from the editor form
+type metadataTags []string
+
+// UnmarshalJSON accepts an array of tags, or a comma-separated string as
+// sent by older clients
+func (t *metadataTags) UnmarshalJSON(b []byte) error {
+	var tags []string
+	if err := json.Unmarshal(b, &tags); err == nil {
+		*t = nil
+		for _, tag := range tags {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				*t = append(*t, tag)
+			}
+		}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*t = jsontools.ParseTags(s)
+	return nil
+}
+
+// UnmarshalParam parses the comma-separated form field
+func (t *metadataTags) UnmarshalParam(param string) error {
+	*t = jsontools.ParseTags(param)
+	return nil
+}
+
+// UpdateMetadataRequest represents the request body for editing a key's metadata
+type UpdateMetadataRequest struct {
+	Key            string       `json:"key" form:"meta_key"`
+	Description    string       `json:"description" form:"meta_description"`
+	Context        string       `json:"context" form:"meta_context"`
+	MaxLength      int          `json:"max_length" form:"meta_max_length"`
+	Tags           metadataTags `json:"tags" form:"meta_tags"`
+	Screenshot     string       `json:"screenshot" form:"meta_screenshot"`
+	DoNotTranslate bool         `json:"do_not_translate" form:"meta_do_not_translate"`
+}
+
+// UpdateMetadata handles POST /api/project/:id/metadata
+func (h *MetadataHandler) UpdateMetadata(c echo.Context) error {
+	projectID := c.Param("id")
+
+	data, err := loadProjectData(h.db, projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	// Only the owner can describe keys
+	if data.Project.SessionToken != session.GetSessionToken(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+	}
+
+	var req UpdateMetadataRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	if _, ok := data.BaseFlat[req.Key]; !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown key"})
+	}
+	if req.MaxLength < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "max_length must not be negative"})
+	}
+
+	meta := models.KeyMetadata{
+		ProjectID:      projectID,
+		Key:            req.Key,
+		Description:    strings.TrimSpace(req.Description),
+		Context:        strings.TrimSpace(req.Context),
+		MaxLength:      req.MaxLength,
+		Tags:           req.Tags,
+		Screenshot:     strings.TrimSpace(req.Screenshot),
+		DoNotTranslate: req.DoNotTranslate,
+		UpdatedAt:      time.Now(),
+	}
+
+	if meta.IsEmpty() {
+		err = h.db.DeleteKeyMetadata(projectID, req.Key)
+	} else {
+		err = h.db.UpsertKeyMetadata(&meta)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save metadata"})
+	}
+	data.Meta[req.Key] = meta
+
+	// HTMX requests get the re-rendered field back
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return renderField(c, h.db, data, req.Key, "")
+	}
+
+	return c.JSON(http.StatusOK, meta)
+}
+
+// ImportMetadata handles POST /api/project/:id/metadata/import
+// The body is either a sidecar JSON file ({"key": {"description": ...}}) or an ARB file with "@key" entries.
+func (h *MetadataHandler) ImportMetadata(c echo.Context) error {
+	projectID := c.Param("id")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	if project.SessionToken != session.GetSessionToken(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	meta, err := jsontools.ParseMetadataFile(body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := saveKeyMetadata(h.db, projectID, meta); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save metadata"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"imported": len(meta)})
+}
+
+// saveKeyMetadata stores imported metadata for a project
+func saveKeyMetadata(db *database.DB, projectID string, meta map[string]models.KeyMetadata) error {
+	for key, m := range meta {
+		m.ProjectID = projectID
+		m.Key = key
+		m.UpdatedAt = time.Now()
+		if err := db.UpsertKeyMetadata(&m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/ai"
+	"templui/internal/database"
+	"templui/internal/models"
+	"templui/internal/session"
+)
+
+type MTHandler struct {
+	db *database.DB
+}
+
+func NewMTHandler(db *database.DB) *MTHandler {
+	return &MTHandler{db: db}
+}
+
+// GetSettings handles GET /api/project/:id/mt
+// Returns the project's machine translation settings and the providers this
+// installation has configured.
+func (h *MTHandler) GetSettings(c echo.Context) error {
+	project, err := h.db.GetProject(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"settings":  project.MTSettings,
+		"defaults":  ai.DefaultProviders(),
+		"providers": ai.AvailableProviders(),
+	})
+}
+
+// UpdateSettings handles POST /api/project/:id/mt
+// Body: {"providers": [{"provider": "deepl"}, {"provider": "openai", "model": "gpt-4o-mini"}],
+// "languages": {"ja": [{"provider": "openai"}]}, "monthly_budget": 20}
+func (h *MTHandler) UpdateSettings(c echo.Context) error {
+	projectID := c.Param("id")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	// Only the owner can change project configuration
+	if project.SessionToken != session.GetSessionToken(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+	}
+
+	var req models.MTSettings
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	if req.MonthlyBudget < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "monthly_budget must not be negative"})
+	}
+
+	lists := [][]models.MTProvider{req.Providers}
+	for _, providers := range req.Languages {
+		lists = append(lists, providers)
+	}
+	for _, providers := range lists {
+		for _, p := range providers {
+			if !ai.IsProvider(p.Provider) {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown provider: " + p.Provider})
+			}
+		}
+	}
+
+	if err := h.db.UpdateProjectMTSettings(projectID, req); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update settings"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"settings": req})
+}
+
+// GetStyleGuide handles GET /api/project/:id/style
+func (h *MTHandler) GetStyleGuide(c echo.Context) error {
+	project, err := h.db.GetProject(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	return c.JSON(http.StatusOK, project.StyleGuide)
+}
+
+// UpdateStyleGuide handles POST /api/project/:id/style
+// Body: {"formality": "informal", "tone": "friendly", "audience": "developers", "notes": "..."}
+func (h *MTHandler) UpdateStyleGuide(c echo.Context) error {
+	projectID := c.Param("id")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	// Only the owner can change project configuration
+	if project.SessionToken != session.GetSessionToken(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+	}
+
+	var req models.StyleGuide
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	if !models.ValidFormality(req.Formality) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "formality must be formal, informal or empty"})
+	}
+
+	if err := h.db.UpdateProjectStyleGuide(projectID, req); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update style guide"})
+	}
+	return c.JSON(http.StatusOK, req)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/labstack/gommon/log"
+
+	"templui/internal/ai"
+	"templui/internal/database"
+	"templui/internal/models"
+)
+
+// mtCache reuses machine translations of identical source texts with the same
+// prompt context, across projects. Only the first provider of a project is
+// looked up; a nil cache is disabled.
+type mtCache struct {
+	db         *database.DB
+	translator string
+	ttl        time.Duration
+}
+
+// newMTCache creates the cache for a provider fallback order and purges
+// expired entries. It returns nil when MT_CACHE_TTL disables the cache.
+func newMTCache(db *database.DB, providers []models.MTProvider) *mtCache {
+	ttl := ai.CacheTTL()
+	if ttl == 0 {
+		return nil
+	}
+	if len(providers) == 0 {
+		providers = ai.DefaultProviders()
+	}
+	if _, err := db.PurgeMTCache(time.Now()); err != nil {
+		log.Warnf("Failed to purge the machine translation cache: %v", err)
+	}
+	return &mtCache{db: db, translator: ai.TranslatorName(providers[0]), ttl: ttl}
+}
+
+// lookup returns the cached translations of the request texts by key
+func (c *mtCache) lookup(req ai.Request) map[string]string {
+	hits := make(map[string]string)
+	if c == nil {
+		return hits
+	}
+	keys := make(map[string]string) // Cache key -> text key
+	cacheKeys := make([]string, 0, len(req.Texts))
+	for k, v := range req.Texts {
+		if v == "" {
+			continue
+		}
+		cacheKey := req.CacheKey(c.translator, k)
+		keys[cacheKey] = k
+		cacheKeys = append(cacheKeys, cacheKey)
+	}
+	translations, err := c.db.GetCachedTranslations(cacheKeys, time.Now())
+	if err != nil {
+		log.Warnf("Machine translation cache lookup failed: %v", err)
+		return hits
+	}
+	for cacheKey, translation := range translations {
+		hits[keys[cacheKey]] = translation
+	}
+	return hits
+}
+
+// store caches the translations of a batch that passed validation
+func (c *mtCache) store(req ai.Request, r batchResult, v *mtValidator) {
+	if c == nil || r.err != nil {
+		return
+	}
+	req.Texts = r.texts
+	provider, model, _ := strings.Cut(r.translator, "/")
+	now := time.Now()
+	var entries []models.MTCacheEntry
+	for k, value := range r.translations {
+		source, ok := r.texts[k]
+		if !ok || source == "" || value == "" {
+			continue
+		}
+		if problem, _ := v.check(k, value); problem != "" {
+			continue
+		}
+		entries = append(entries, models.MTCacheEntry{
+			Key:         req.CacheKey(r.translator, k),
+			Provider:    provider,
+			Model:       model,
+			SourceLang:  req.SourceLang,
+			TargetLang:  req.TargetLang,
+			SourceText:  source,
+			ContextHash: req.ContextHash(k),
+			Translation: value,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(c.ttl),
+		})
+	}
+	if err := c.db.CacheTranslations(entries); err != nil {
+		log.Warnf("Failed to cache %d machine translations: %v", len(entries), err)
+	}
+}
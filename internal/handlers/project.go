@@ -6,26 +6,31 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 
-	"templui/internal/ai"
 	"templui/internal/database"
+	"templui/internal/events"
+	"templui/internal/jobs"
 	"templui/internal/jsontools"
 	"templui/internal/models"
+	"templui/internal/qa"
 	"templui/internal/session"
 	"templui/ui/pages"
 )
 
 type ProjectHandler struct {
-	db *database.DB
+	db    *database.DB
+	queue *jobs.Queue
 }
 
-func NewProjectHandler(db *database.DB) *ProjectHandler {
-	return &ProjectHandler{db: db}
+func NewProjectHandler(db *database.DB, queue *jobs.Queue) *ProjectHandler {
+	return &ProjectHandler{db: db, queue: queue}
 }
 
 // CreateProjectRequest represents the request body for creating a project
@@ -36,6 +41,7 @@ type CreateProjectRequest struct {
 	BaseLanguage   string `json:"base_language"`   // e.g., "en"
 	TargetLanguage string `json:"target_language"` // e.g., "es"
 	IsLocked       bool   `json:"is_locked"`       // Whether to lock project with secret key
+	MetadataFile   string `json:"metadata_file"`   // Optional sidecar JSON with per-key metadata
 }
 
 // CreateProject handles POST /api/project
@@ -50,6 +56,31 @@ func (h *ProjectHandler) CreateProject(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid base file: %v", err)})
 	}
 
+	// ARB files carry key metadata in "@key" entries; move it out of the translatable content
+	baseContent, meta, err := stripARBMetadata(req.BaseFile)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid base file: %v", err)})
+	}
+	req.BaseFile = baseContent
+	if req.TargetFile != "" {
+		if targetContent, _, err := stripARBMetadata(req.TargetFile); err == nil {
+			req.TargetFile = targetContent
+		}
+	}
+	if req.MetadataFile != "" {
+		sidecar, err := jsontools.ParseMetadataFile([]byte(req.MetadataFile))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid metadata file: %v", err)})
+		}
+		for key, m := range sidecar {
+			existing, ok := meta[key]
+			if !ok {
+				existing = models.KeyMetadata{Key: key}
+			}
+			meta[key] = existing.Merge(m)
+		}
+	}
+
 	// If TargetFile not provided, generate an "empty" version with same keys/shape.
 	if req.TargetFile == "" {
 		var base any
@@ -74,6 +105,12 @@ func (h *ProjectHandler) CreateProject(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid target file: %v", err)})
 	}
 
+	// Imported translations get the same placeholder and markup checks as
+	// manual edits. Values that fail are imported and flagged for review.
+	baseData, _ := jsontools.ParseJSON([]byte(req.BaseFile))
+	targetData, _ := jsontools.ParseJSON([]byte(req.TargetFile))
+	failures := jsontools.ValidateFlat(jsontools.FlattenJSON(baseData, ""), jsontools.FlattenJSON(targetData, ""))
+
 	// Generate project ID
 	projectID := generateID()
 
@@ -135,6 +172,29 @@ func (h *ProjectHandler) CreateProject(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create target file"})
 	}
 
+	// Record the imported translations as the first revision of each key
+	author := requestAuthor(c, h.db, projectID)
+	importedBase := jsontools.FlattenJSON(baseData, "")
+	imported := buildRevisions(targetFile, importedBase, map[string]string{}, jsontools.FlattenJSON(targetData, ""), author, models.SourceImport)
+	if err := h.db.CreateRevisions(imported); err != nil {
+		log.Errorf("Failed to record import history: %v", err)
+	}
+	for key := range failures {
+		if err := h.db.SetStatus(targetFile.ID, key, models.StatusNeedsReview, author.ID); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to flag invalid translations"})
+		}
+	}
+	// Invalid values are not worth reusing
+	remembered := slices.DeleteFunc(imported, func(r models.Revision) bool {
+		_, failed := failures[r.Key]
+		return failed
+	})
+	rememberTranslations(h.db, targetFile, importedBase, remembered)
+
+	if err := saveKeyMetadata(h.db, projectID, meta); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save key metadata"})
+	}
+
 	// Generate API key
 	apiKey, keyHash := generateAPIKey()
 	apiKeyRecord := &models.APIKey{
@@ -158,6 +218,9 @@ func (h *ProjectHandler) CreateProject(c echo.Context) error {
 	if req.IsLocked {
 		response["secret_key"] = secretKey
 	}
+	if len(failures) > 0 {
+		response["validation"] = failures
+	}
 
 	return c.JSON(http.StatusCreated, response)
 }
@@ -192,18 +255,59 @@ func (h *ProjectHandler) GetDiff(c echo.Context) error {
 	baseFlat := jsontools.FlattenJSON(baseData, "")
 	targetFlat := jsontools.FlattenJSON(targetData, "")
 
+	sources, err := h.db.GetSourceHashes(targetFile.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get source hashes"})
+	}
+
 	// Compare
-	diff := jsontools.CompareJSON(baseFlat, targetFlat)
+	diff := jsontools.CompareJSONWithSources(baseFlat, targetFlat, sources)
 	completion := jsontools.CompletionPercentage(baseFlat, targetFlat)
+	if c.QueryParam("exclude_outdated") == "true" {
+		completion = jsontools.CompletionPercentageExcludingOutdated(baseFlat, targetFlat, sources)
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"missing_keys":     diff.MissingKeys,
+		"outdated_keys":    diff.OutdatedKeys,
 		"extra_keys":       diff.ExtraKeys,
 		"different_values": diff.DifferentValues,
 		"completion":       completion,
 	})
 }
 
+// GetField handles GET /api/project/:id/field?key=
+// Renders a single translation field, used by the editor to refresh keys
+// saved by someone else
+func (h *ProjectHandler) GetField(c echo.Context) error {
+	key := c.QueryParam("key")
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if _, ok := data.BaseFlat[key]; !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Key not found"})
+	}
+	return renderField(c, h.db, data, key, "")
+}
+
+// GetFields handles GET /api/project/:id/fields?key=&key=
+// Renders several translation fields as out-of-band swaps, used by the editor
+// to refresh the keys of a batch saved by someone else. Unknown keys are skipped.
+func (h *ProjectHandler) GetFields(c echo.Context) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	keys := make([]string, 0, len(c.QueryParams()["key"]))
+	for _, key := range c.QueryParams()["key"] {
+		if _, ok := data.BaseFlat[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return renderFields(c, h.db, data, keys)
+}
+
 // UpdateTranslation handles POST /api/project/:id/translations
 func (h *ProjectHandler) UpdateTranslation(c echo.Context) error {
 	projectID := c.Param("id")
@@ -236,90 +340,168 @@ func (h *ProjectHandler) UpdateTranslation(c echo.Context) error {
 
 			baseVal := demoBase[key]
 
-			if err := jsontools.ValidatePlaceholders(baseVal, value); err != nil {
+			if err := jsontools.ValidateValue(baseVal, value); err != nil {
 				c.Response().Header().Set("HX-Retarget", fmt.Sprintf("#field-%s", key))
 				c.Response().Header().Set("HX-Reswap", "outerHTML")
 				// Override hx-select to pick the whole field
 				c.Response().Header().Set("HX-Reselect", fmt.Sprintf("#field-%s", key))
-				return render(c, pages.TranslationField(key, baseVal, value, projectID, err.Error()))
+				return render(c, pages.TranslationField(key, baseVal, value, projectID, err.Error(), pages.FieldState{}))
 			}
 
-			return render(c, pages.TranslationField(key, baseVal, value, projectID, ""))
+			return render(c, pages.TranslationField(key, baseVal, value, projectID, "", pages.FieldState{}))
 		}
 		return c.JSON(http.StatusOK, map[string]string{"status": "success"})
 	}
 
-	// Get target file
-	files, err := h.db.GetFilesByProject(projectID)
+	data, err := loadProjectData(h.db, projectID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get files"})
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 	}
+	baseFlat, targetFlat := data.BaseFlat, data.TargetFlat
+	role := requestRole(c, h.db, data.Project)
 
-	var targetFile *models.TranslationFile
-	var baseFlat map[string]string
-	for i := range files {
-		if files[i].FileType == "target" {
-			targetFile = &files[i]
-		} else if files[i].FileType == "base" {
-			// Get base file for rendering
-			var baseData map[string]interface{}
-			json.Unmarshal([]byte(files[i].Content), &baseData)
-			baseFlat = jsontools.FlattenJSON(baseData, "")
+	// Ignore form fields that are not translation keys
+	for key := range req {
+		if _, ok := baseFlat[key]; !ok {
+			delete(req, key)
 		}
 	}
 
-	if targetFile == nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "Target file not found"})
+	// Suggesters cannot write translations directly
+	if role == models.RoleSuggester {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "This link can only suggest translations"})
 	}
-
-	// Parse existing content
-	var targetData map[string]interface{}
-	json.Unmarshal([]byte(targetFile.Content), &targetData)
-
-	// Flatten
-	targetFlat := jsontools.FlattenJSON(targetData, "")
-
-	// Update with new values
+	// Approved values are locked for translators
 	for key, value := range req {
-		// Validate placeholders
-		baseVal := baseFlat[key]
-		if err := jsontools.ValidatePlaceholders(baseVal, value); err != nil {
-			// interpolated string error here. This works but when redoing it we skip this.
-			c.Response().Header().Set("HX-Retarget", fmt.Sprintf("#field-%s", key))
-			c.Response().Header().Set("HX-Reswap", "outerHTML")
-			c.Response().Header().Set("HX-Reselect", fmt.Sprintf("#field-%s", key)) // Override hx-select to pick the whole field
-			return render(c, pages.TranslationField(key, baseVal, value, projectID, err.Error()))
+		if value != targetFlat[key] && data.locked(key, role) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": fmt.Sprintf("%s is approved and can only be changed by owners and reviewers", key)})
 		}
-		targetFlat[key] = value
 	}
 
-	// Unflatten back
-	updatedData := jsontools.UnflattenJSON(targetFlat)
-
-	// Convert to JSON
-	updatedJSON, _ := json.MarshalIndent(updatedData, "", "  ")
-
-	// Save to database
-	if err := h.db.UpdateFile(targetFile.ID, string(updatedJSON)); err != nil {
+	// Validate and save, recording a revision for every changed key
+	report, failedKey, message, err := applyTranslations(h.db, data, req, requestAuthor(c, h.db, projectID), models.SourceManual)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update file"})
 	}
+	if failedKey != "" {
+		// interpolated string error here. This works but when redoing it we skip this.
+		c.Response().Header().Set("HX-Retarget", fmt.Sprintf("#field-%s", failedKey))
+		c.Response().Header().Set("HX-Reswap", "outerHTML")
+		c.Response().Header().Set("HX-Reselect", fmt.Sprintf("#field-%s", failedKey)) // Override hx-select to pick the whole field
+		return render(c, pages.TranslationField(failedKey, baseFlat[failedKey], req[failedKey], projectID, message, data.fieldState(failedKey, nil, role)))
+	}
 
 	for key := range req {
 		// can't we just swap the whole input or div around it? this way we can easily replace old error message.
-		return render(c, pages.TranslationField(key, baseFlat[key], targetFlat[key], projectID, ""))
+		return render(c, pages.TranslationField(key, baseFlat[key], targetFlat[key], projectID, "", data.fieldState(key, report.ForKey(key), role)))
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
 }
 
+// checkTranslations validates new values against placeholders, markup and
+// error-level QA rules without saving them. It returns the QA report of the
+// values and, when one is rejected, its key and message.
+func checkTranslations(data *projectData, values map[string]string) (qa.Report, string, string) {
+	// Check against a copy, the values are not saved yet
+	target := data.TargetFlat
+	defer func() { data.TargetFlat = target }()
+	data.TargetFlat = maps.Clone(target)
+
+	keys := make([]string, 0, len(values))
+	for key, value := range values {
+		if err := jsontools.ValidateValue(data.BaseFlat[key], value); err != nil {
+			return qa.Report{}, key, err.Error()
+		}
+		data.TargetFlat[key] = value
+		keys = append(keys, key)
+	}
+	report := runQAForKeys(data, keys)
+	for _, key := range keys {
+		for _, issue := range report.ForKey(key) {
+			if issue.Severity == qa.SeverityError {
+				return report, key, issue.Message
+			}
+		}
+	}
+	return report, "", ""
+}
+
+// applyTranslations validates new values against placeholders, markup and
+// error-level QA rules, then saves them. When a value is rejected nothing is
+// saved and the offending key and message are returned.
+func applyTranslations(db *database.DB, data *projectData, values map[string]string, author changeAuthor, source string) (qa.Report, string, string, error) {
+	baseFlat, targetFlat := data.BaseFlat, data.TargetFlat
+
+	report, failedKey, message := checkTranslations(data, values)
+	if failedKey != "" {
+		return report, failedKey, message, nil
+	}
+
+	// Update with new values
+	before := maps.Clone(targetFlat)
+	maps.Copy(targetFlat, values)
+
+	// Save to database, recording a revision for every changed key
+	if _, err := saveTarget(db, data.TargetFile, baseFlat, before, targetFlat, author, source); err != nil {
+		return report, "", "", err
+	}
+
+	// Saving a translation confirms it against the current source text
+	reviewed := make([]string, 0, len(values))
+	confirmed := make(map[string]string, len(values))
+	for key, value := range values {
+		reviewed = append(reviewed, key)
+		delete(data.Reviews, key)
+		if value != "" {
+			confirmed[key] = jsontools.SourceHash(baseFlat[key])
+			data.Sources[key] = confirmed[key]
+		}
+	}
+	if err := db.ClearReviewFlags(data.Project.ID, data.TargetFile.LanguageCode, reviewed); err != nil {
+		log.Errorf("Failed to clear review flags: %v", err)
+	}
+	if err := db.SetSourceHashes(data.TargetFile.ID, confirmed); err != nil {
+		log.Errorf("Failed to record source hashes: %v", err)
+	}
+	for key := range values {
+		events.Publish(data.Project.ID, events.Event{Type: events.TypeValidation, Data: events.ValidationEvent{Key: key, Issues: report.ForKey(key)}})
+	}
+
+	return report, "", "", nil
+}
+
 // ExportFile handles GET /api/project/:id/export
 func (h *ProjectHandler) ExportFile(c echo.Context) error {
 	projectID := c.Param("id")
 	lang := c.QueryParam("lang")
+	approved := c.QueryParam("approved") // "only", "base" or "draft"
 
-	files, err := h.db.GetFilesByProject(projectID)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get files"})
+	switch approved {
+	case "", "only", "base", "draft":
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "approved must be only, base or draft"})
+	}
+
+	var files []models.TranslationFile
+	if ref := c.QueryParam("snapshot"); ref != "" {
+		if approved != "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Snapshots cannot be filtered by status"})
+		}
+		// Export from an immutable snapshot instead of the working copy
+		snapshot, err := h.db.GetSnapshot(projectID, ref)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Snapshot not found"})
+		}
+		for _, f := range snapshot.Files {
+			files = append(files, models.TranslationFile{FileType: f.FileType, LanguageCode: f.LanguageCode, Content: f.Content})
+		}
+	} else {
+		var err error
+		files, err = h.db.GetFilesByProject(projectID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get files"})
+		}
 	}
 
 	var targetFile *models.TranslationFile
@@ -334,94 +516,145 @@ func (h *ProjectHandler) ExportFile(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "File not found"})
 	}
 
+	content := targetFile.Content
+	if approved != "" && targetFile.FileType == "target" {
+		filtered, err := h.approvedContent(files, targetFile, approved)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to filter translations"})
+		}
+		content = filtered
+	}
+
 	c.Response().Header().Set("Content-Type", "application/json")
 	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", targetFile.LanguageCode))
 
-	return c.String(http.StatusOK, targetFile.Content)
+	return c.String(http.StatusOK, content)
 }
 
-// AutoTranslate handles POST /api/project/:id/translate
-func (h *ProjectHandler) AutoTranslate(c echo.Context) error {
-	projectID := c.Param("id")
-
-	// Get files
-	files, err := h.db.GetFilesByProject(projectID)
+// approvedContent builds an export of a target file in which only approved
+// values are kept as is. Other keys are left out ("only"), replaced by the base
+// value ("base"), or keep their current value and fall back to the base value
+// when untranslated ("draft").
+func (h *ProjectHandler) approvedContent(files []models.TranslationFile, targetFile *models.TranslationFile, fallback string) (string, error) {
+	statuses, err := h.db.GetStatuses(targetFile.ID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get files"})
+		return "", err
 	}
 
-	var baseFile, targetFile *models.TranslationFile
-	for i := range files {
-		if files[i].FileType == "base" {
-			baseFile = &files[i]
-		} else if files[i].FileType == "target" {
-			targetFile = &files[i]
-		}
-	}
-
-	if baseFile == nil || targetFile == nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "Files not found"})
+	baseFile, _ := findProjectFiles(files)
+	baseFlat := map[string]string{}
+	if baseFile != nil {
+		baseData, _ := jsontools.ParseJSON([]byte(baseFile.Content))
+		baseFlat = jsontools.FlattenJSON(baseData, "")
 	}
-
-	// Parse JSON
-	baseData, _ := jsontools.ParseJSON([]byte(baseFile.Content))
 	targetData, _ := jsontools.ParseJSON([]byte(targetFile.Content))
-
-	// Flatten
-	baseFlat := jsontools.FlattenJSON(baseData, "")
 	targetFlat := jsontools.FlattenJSON(targetData, "")
+	for key := range baseFlat {
+		if _, ok := targetFlat[key]; !ok {
+			targetFlat[key] = ""
+		}
+	}
 
-	// Identify missing translations
-	missing := make(map[string]string)
-	for k, v := range baseFlat {
-		if targetFlat[k] == "" {
-			missing[k] = v
+	result := make(map[string]string, len(targetFlat))
+	for key, value := range targetFlat {
+		if value != "" && statuses[key].Status == models.StatusApproved {
+			result[key] = value
+			continue
+		}
+		switch fallback {
+		case "base":
+			result[key] = baseFlat[key]
+		case "draft":
+			result[key] = value
+			if value == "" {
+				result[key] = baseFlat[key]
+			}
 		}
 	}
 
-	log.Infof("Found %d missing translations for project %s", len(missing), projectID)
+	b, err := json.MarshalIndent(jsontools.UnflattenJSON(result), "", "  ")
+	return string(b), err
+}
+
+// AutoTranslate handles POST /api/project/:id/translate
+// Queues an auto_translate job and returns its ID, or the progress fragment
+// for the editor. The optional keys, prefix, view and status fields limit the
+// keys to translate, retranslate replaces their existing values too.
+func (h *ProjectHandler) AutoTranslate(c echo.Context) error {
+	projectID := c.Param("id")
 
-	if len(missing) == 0 {
-		return c.JSON(http.StatusOK, map[string]string{"message": "Nothing to translate"})
+	data, err := loadProjectData(h.db, projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	// Machine translation costs money and overwrites values
+	if !requireEditor(c, h.db, data.Project) {
+		return nil
 	}
 
-	// Limit to avoid timeouts/costs (optional)
-	if len(missing) > 50 {
-		// Log warning or limit? For now just proceed
-		log.Warnf("Large batch of translations: %d keys", len(missing))
+	var req autoTranslateParams
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	if !slices.Contains(editorViews, req.View) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid view"})
+	}
+	if req.Status != "" && !models.ValidStatus(req.Status) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+	}
+	if role := requestRole(c, h.db, data.Project); req.IncludeApproved && role != models.RoleOwner && role != models.RoleReviewer {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only owners and reviewers can re-translate approved values"})
 	}
 
-	// Call AI
-	aiClient := ai.NewOpenAIClient()
-	translations, err := aiClient.Translate(baseFile.LanguageCode, targetFile.LanguageCode, missing)
+	author := requestAuthor(c, h.db, projectID)
+	req.AuthorType, req.AuthorID = author.Type, author.ID
+	params, _ := json.Marshal(req)
+	job := &models.Job{
+		ID:        generateID(),
+		Type:      models.JobAutoTranslate,
+		ProjectID: projectID,
+		Owner:     models.OwnerID(session.GetSessionToken(c)),
+		Params:    params,
+	}
+	// Only one run writes a project's values at a time. A second click while a
+	// run is queued or running gets that run, so the provider is not paid twice
+	// for the same keys; a run with other options is refused until it is done.
+	queued, err := h.queue.EnqueueUnique(job)
 	if err != nil {
-		log.Errorf("AI Translation failed: %v", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("AI Translation failed: %v", err)})
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to queue translation"})
 	}
-
-	// Update target flat
-	for k, v := range translations {
-		targetFlat[k] = v
+	if queued.ID == job.ID {
+		return renderJob(c, job, http.StatusAccepted)
 	}
-
-	// Save back
-	updatedData := jsontools.UnflattenJSON(targetFlat)
-	updatedJSON, _ := json.MarshalIndent(updatedData, "", "  ")
-
-	if err := h.db.UpdateFile(targetFile.ID, string(updatedJSON)); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save translations"})
+	var active autoTranslateParams
+	if err := json.Unmarshal(queued.Params, &active); err == nil && active.sameRun(req) {
+		return renderJob(c, queued, http.StatusOK)
 	}
-
-	// Return the new list of fields to swap
-	// We want to trigger a refresh of the form or just swap the fields that changed.
-	// Easiest is to respond with HX-Trigger to refresh page or return the updated fields.
-	// Let's reload page for simplicity for now, OR return success and let client handle.
-	c.Response().Header().Set("HX-Refresh", "true")
-	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	if c.Request().Header.Get("HX-Request") == "true" {
+		// The editor shows the progress of the active run instead
+		return renderJob(c, queued, http.StatusOK)
+	}
+	return c.JSON(http.StatusConflict, map[string]interface{}{
+		"error": "Another auto-translation with different options is in progress",
+		"job":   queued,
+	})
 }
 
 // Helper functions
 
+// findProjectFiles returns the base and target file from a project's files
+func findProjectFiles(files []models.TranslationFile) (*models.TranslationFile, *models.TranslationFile) {
+	var baseFile, targetFile *models.TranslationFile
+	for i := range files {
+		if files[i].FileType == "base" {
+			baseFile = &files[i]
+		} else if files[i].FileType == "target" && targetFile == nil {
+			targetFile = &files[i]
+		}
+	}
+	return baseFile, targetFile
+}
+
 func generateID() string {
 	b := make([]byte, 16)
 	rand.Read(b)
@@ -440,6 +673,26 @@ func generateAPIKey() (string, string) {
 	return key, keyHash
 }
 
+// stripARBMetadata removes ARB "@key" and "@@locale" entries from a JSON file and returns
+// the remaining content along with the extracted metadata. Content without ARB entries is returned unchanged.
+func stripARBMetadata(content string) (string, map[string]models.KeyMetadata, error) {
+	data, err := jsontools.ParseJSON([]byte(content))
+	if err != nil {
+		return "", nil, err
+	}
+
+	stripped, meta := jsontools.ExtractARBMetadata(data)
+	if len(stripped) == len(data) {
+		return content, meta, nil
+	}
+
+	b, err := json.MarshalIndent(stripped, "", "  ")
+	if err != nil {
+		return "", nil, err
+	}
+	return string(b), meta, nil
+}
+
 // makeTranslationSkeleton preserves the JSON structure but blanks out leaf values.
 // - objects: recurse into fields
 // - arrays: recurse into elements
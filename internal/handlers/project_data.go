@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/jsontools"
+	"templui/internal/models"
+	"templui/internal/qa"
+	"templui/ui/pages"
+)
+
+// projectData holds a project together with its parsed base and target files
+type projectData struct {
+	Project    *models.Project
+	BaseFile   *models.TranslationFile
+	TargetFile *models.TranslationFile
+	BaseFlat   map[string]string
+	TargetFlat map[string]string
+	Meta       map[string]models.KeyMetadata
+	Regions    map[string][]models.ScreenshotRegion // Screenshot regions by key
+	Reviews    map[string]models.ReviewFlag         // Target translations needing review by key
+	Sources    map[string]string                    // Source hash each target value was translated from
+	Statuses   map[string]models.TranslationStatus  // Stored workflow statuses by key
+	Suggested  map[string]int                       // Open suggestions by key
+	Questions  map[string]int                       // Unresolved comment threads by key
+	Quality    map[string]models.QualityAssessment  // AI quality assessments by key, possibly of older values
+	Glossary   []models.GlossaryTerm                // Project and linked glossary terms
+	Terms      []qa.Term                            // Glossary terms for the target language
+
+	termFinder *qa.TermFinder // Finds Terms in base values, created on first use
+}
+
+// loadProjectData loads a project, flattens its files and loads key metadata
+func loadProjectData(db *database.DB, projectID string) (*projectData, error) {
+	project, err := db.GetProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	files, err := db.GetFilesByProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get files: %w", err)
+	}
+
+	baseFile, targetFile := findProjectFiles(files)
+	if baseFile == nil || targetFile == nil {
+		return nil, fmt.Errorf("project must have both base and target files")
+	}
+
+	meta, err := db.GetKeyMetadataByProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key metadata: %w", err)
+	}
+
+	regions, err := db.GetScreenshotRegionsByProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screenshot regions: %w", err)
+	}
+
+	reviews, err := db.GetReviewFlags(projectID, targetFile.LanguageCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review flags: %w", err)
+	}
+
+	sources, err := db.GetSourceHashes(targetFile.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source hashes: %w", err)
+	}
+
+	statuses, err := db.GetStatuses(targetFile.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statuses: %w", err)
+	}
+
+	suggested, err := db.CountOpenSuggestions(targetFile.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count suggestions: %w", err)
+	}
+
+	questions, err := db.CountOpenCommentThreads(projectID, targetFile.LanguageCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	quality, err := db.GetQualityAssessments(targetFile.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quality assessments: %w", err)
+	}
+
+	glossary, err := loadGlossary(db, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get glossary: %w", err)
+	}
+
+	baseData, _ := jsontools.ParseJSON([]byte(baseFile.Content))
+	targetData, _ := jsontools.ParseJSON([]byte(targetFile.Content))
+
+	return &projectData{
+		Project:    project,
+		BaseFile:   baseFile,
+		TargetFile: targetFile,
+		BaseFlat:   jsontools.FlattenJSON(baseData, ""),
+		TargetFlat: jsontools.FlattenJSON(targetData, ""),
+		Meta:       meta,
+		Regions:    regions,
+		Reviews:    reviews,
+		Sources:    sources,
+		Statuses:   statuses,
+		Suggested:  suggested,
+		Questions:  questions,
+		Quality:    quality,
+		Glossary:   glossary,
+		Terms:      glossaryTerms(glossary, targetFile.LanguageCode),
+	}, nil
+}
+
+// status returns the workflow status of a key. Empty values are always
+// untranslated; values without a stored status count as translated.
+func (d *projectData) status(key string) string {
+	if d.TargetFlat[key] == "" {
+		return models.StatusUntranslated
+	}
+	if s, ok := d.Statuses[key]; ok {
+		return s.Status
+	}
+	return models.StatusTranslated
+}
+
+// locked reports whether a key's value is approved and the role may not change it
+func (d *projectData) locked(key, role string) bool {
+	return d.status(key) == models.StatusApproved && role != models.RoleOwner && role != models.RoleReviewer
+}
+
+// statusCounts counts the base keys per workflow status
+func (d *projectData) statusCounts() map[string]int {
+	counts := make(map[string]int, len(models.Statuses))
+	for _, status := range models.Statuses {
+		counts[status] = 0
+	}
+	for key := range d.BaseFlat {
+		counts[d.status(key)]++
+	}
+	return counts
+}
+
+// diff compares the target with the base, including outdated translations
+func (d *projectData) diff() jsontools.Difference {
+	return jsontools.CompareJSONWithSources(d.BaseFlat, d.TargetFlat, d.Sources)
+}
+
+// isOutdated reports whether the key was translated from a different base value
+func (d *projectData) isOutdated(key string) bool {
+	hash, ok := d.Sources[key]
+	return ok && d.TargetFlat[key] != "" && hash != jsontools.SourceHash(d.BaseFlat[key])
+}
+
+// quality returns the quality assessment of a key's current value, or nil
+// when it was not assessed or changed since
+func (d *projectData) quality(key string) *models.QualityAssessment {
+	a, ok := d.Quality[key]
+	if !ok || d.TargetFlat[key] == "" || a.TargetHash != jsontools.SourceHash(d.TargetFlat[key]) {
+		return nil
+	}
+	return &a
+}
+
+// lowConfidenceKeys lists the base keys whose current value scored below models.LowQualityScore
+func (d *projectData) lowConfidenceKeys() []string {
+	var keys []string
+	for key := range d.BaseFlat {
+		if a := d.quality(key); a != nil && a.IsLowConfidence() {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// filterKeys returns the sorted keys of an editor view ("missing",
+// "outdated", "questions", "low_confidence" or all keys), optionally only those
+// with a workflow status
+func (d *projectData) filterKeys(view, status string) []string {
+	var keys []string
+	switch view {
+	case "missing":
+		keys = d.diff().MissingKeys
+	case "outdated":
+		// Keys whose source text changed since translation
+		keys = d.diff().OutdatedKeys
+	case "questions":
+		// Keys with unresolved comment threads
+		keys = make([]string, 0, len(d.Questions))
+		for key := range d.Questions {
+			if _, ok := d.BaseFlat[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+	case "low_confidence":
+		// Keys the AI quality review scored low
+		keys = d.lowConfidenceKeys()
+	default:
+		keys = make([]string, 0, len(d.BaseFlat))
+		for key := range d.BaseFlat {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	if status != "" {
+		filtered := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if d.status(key) == status {
+				filtered = append(filtered, key)
+			}
+		}
+		keys = filtered
+	}
+	return keys
+}
+
+// fieldState builds the per-key annotations rendered next to a translation field
+func (d *projectData) fieldState(key string, issues []qa.Issue, role string) pages.FieldState {
+	state := pages.FieldState{
+		Issues:      issues,
+		Meta:        d.Meta[key],
+		Regions:     d.Regions[key],
+		IsOwner:     role == models.RoleOwner,
+		Outdated:    d.isOutdated(key),
+		Status:      d.status(key),
+		Role:        role,
+		Suggestions: d.Suggested[key],
+		Questions:   d.Questions[key],
+		Terms:       d.findTerms(d.BaseFlat[key]),
+		Quality:     d.quality(key),
+	}
+	if flag, ok := d.Reviews[key]; ok {
+		state.Review = &flag
+	}
+	return state
+}
+
+// findTerms returns the glossary terms used in a text
+func (d *projectData) findTerms(text string) []qa.TermMatch {
+	if d.termFinder == nil {
+		d.termFinder = qa.NewTermFinder(d.Terms)
+	}
+	return d.termFinder.Find(text)
+}
+
+// renderField renders a single translation field with fresh QA results
+func renderField(c echo.Context, db *database.DB, d *projectData, key, errorMessage string) error {
+	role := requestRole(c, db, d.Project)
+	report := runQAForKeys(d, []string{key})
+	return render(c, pages.TranslationField(key, d.BaseFlat[key], d.TargetFlat[key], d.Project.ID, errorMessage, d.fieldState(key, report.ForKey(key), role)))
+}
+
+// renderFields renders several translation fields as out-of-band swaps, with
+// one QA run for all of them
+func renderFields(c echo.Context, db *database.DB, d *projectData, keys []string) error {
+	role := requestRole(c, db, d.Project)
+	issues := runQAForKeys(d, keys).ByKey()
+	states := make(map[string]pages.FieldState, len(keys))
+	for _, key := range keys {
+		state := d.fieldState(key, issues[key], role)
+		state.SwapOOB = true
+		states[key] = state
+	}
+	return render(c, pages.TranslationFields(d.Project.ID, keys, d.BaseFlat, d.TargetFlat, states))
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/jobs"
+	"templui/internal/models"
+	"templui/internal/qa"
+	"templui/internal/session"
+)
+
+type QAHandler struct {
+	db    *database.DB
+	queue *jobs.Queue
+}
+
+func NewQAHandler(db *database.DB, queue *jobs.Queue) *QAHandler {
+	return &QAHandler{db: db, queue: queue}
+}
+
+// runQA runs all QA checks configured for the project against the flattened files
+func runQA(project *models.Project, baseFlat, targetFlat map[string]string, meta map[string]models.KeyMetadata, terms []qa.Term) qa.Report {
+	entries := qa.EntriesFromFlat(baseFlat, targetFlat)
+	for i := range entries {
+		entries[i].Terms = terms
+		if m, ok := meta[entries[i].Key]; ok {
+			entries[i].MaxLength = m.MaxLength
+			entries[i].DoNotTranslate = m.DoNotTranslate
+		}
+	}
+	return qa.Run(entries, qa.ParseConfig(project.QARules))
+}
+
+// runQAForKeys runs the QA checks of a project for some keys only. Keys that
+// share a source text with them are checked as well, so cross-key rules like
+// inconsistency see the same entries as in a full run.
+func runQAForKeys(d *projectData, keys []string) qa.Report {
+	sources := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		sources[d.BaseFlat[key]] = true
+	}
+	base := make(map[string]string)
+	target := make(map[string]string)
+	for key, value := range d.BaseFlat {
+		if sources[value] {
+			base[key] = value
+			target[key] = d.TargetFlat[key]
+		}
+	}
+	return runQA(d.Project, base, target, d.Meta, d.Terms)
+}
+
+// GetReport handles GET /api/project/:id/qa
+func (h *QAHandler) GetReport(c echo.Context) error {
+	projectID := c.Param("id")
+
+	data, err := loadProjectData(h.db, projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, runQA(data.Project, data.BaseFlat, data.TargetFlat, data.Meta, data.Terms))
+}
+
+// qaReport runs the QA checks of a project in a qa_report job
+func qaReport(db *database.DB, job *models.Job) (interface{}, error) {
+	data, err := loadProjectData(db, job.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return runQA(data.Project, data.BaseFlat, data.TargetFlat, data.Meta, data.Terms), nil
+}
+
+// QueueReport handles POST /api/project/:id/qa/jobs
+// Runs the QA report in the background for large projects. The report is the
+// job's result.
+func (h *QAHandler) QueueReport(c echo.Context) error {
+	projectID := c.Param("id")
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	if !requireEditor(c, h.db, project) {
+		return nil
+	}
+
+	job := &models.Job{
+		ID:        generateID(),
+		Type:      models.JobQAReport,
+		ProjectID: projectID,
+		Owner:     models.OwnerID(session.GetSessionToken(c)),
+	}
+	if err := h.queue.Enqueue(job); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to queue QA report"})
+	}
+	return renderJob(c, job, http.StatusAccepted)
+}
+
+// ListRules handles GET /api/qa/rules - lists available checks
+func (h *QAHandler) ListRules(c echo.Context) error {
+	type rule struct {
+		ID              string      `json:"id"`
+		Description     string      `json:"description"`
+		DefaultSeverity qa.Severity `json:"default_severity"`
+	}
+	var rules []rule
+	for _, check := range qa.Checks() {
+		rules = append(rules, rule{
+			ID:              check.ID(),
+			Description:     check.Description(),
+			DefaultSeverity: check.DefaultSeverity(),
+		})
+	}
+	return c.JSON(http.StatusOK, rules)
+}
+
+// UpdateRulesRequest represents the request body for configuring QA rules
+type UpdateRulesRequest struct {
+	Rules map[string]string `json:"rules"` // rule ID -> "off", "info", "warning" or "error"
+}
+
+// UpdateRules handles POST /api/project/:id/qa/rules
+func (h *QAHandler) UpdateRules(c echo.Context) error {
+	projectID := c.Param("id")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	// Only the owner can change project configuration
+	if project.SessionToken != session.GetSessionToken(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+	}
+
+	var req UpdateRulesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	for id, sev := range req.Rules {
+		if _, ok := qa.Lookup(id); !ok {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown rule: " + id})
+		}
+		if !qa.Severity(sev).Valid() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid severity for " + id + ": " + sev})
+		}
+	}
+
+	if err := h.db.UpdateProjectQARules(projectID, req.Rules); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update rules"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"rules": req.Rules})
+}
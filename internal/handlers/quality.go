@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+
+	"templui/internal/ai"
+	"templui/internal/database"
+	"templui/internal/events"
+	"templui/internal/jobs"
+	"templui/internal/jsontools"
+	"templui/internal/models"
+	"templui/internal/session"
+)
+
+type QualityHandler struct {
+	db    *database.DB
+	queue *jobs.Queue
+}
+
+func NewQualityHandler(db *database.DB, queue *jobs.Queue) *QualityHandler {
+	return &QualityHandler{db: db, queue: queue}
+}
+
+// qualityReviewParams are the parameters of a quality_review job
+type qualityReviewParams struct {
+	AuthorType    string   `json:"author_type"`
+	AuthorID      string   `json:"author_id"`
+	Keys          []string `json:"keys,omitempty"` // Empty reviews all translated keys
+	Score         bool     `json:"score"`
+	BackTranslate bool     `json:"back_translate"`
+	Force         bool     `json:"force,omitempty"` // Also review values whose assessment is current
+}
+
+// qualityReviewResult is the outcome of a quality_review job
+type qualityReviewResult struct {
+	Assessed      int      `json:"assessed"`
+	Skipped       int      `json:"skipped"`        // Values whose assessment was still current
+	Unscored      []string `json:"unscored"`       // Keys the model returned no score for
+	LowConfidence []string `json:"low_confidence"` // Keys scored below models.LowQualityScore
+	Batches       int      `json:"batches"`
+	Failed        int      `json:"failed_batches"`
+	Translators   []string `json:"translators"`
+	Errors        []string `json:"errors"`
+}
+
+// QueueReview handles POST /api/project/:id/quality
+// Body: {"keys": ["welcome"], "score": true, "back_translate": true}
+// Asks the project's LLM provider to score translated values and/or translate
+// them back into the base language, in the background. Without keys all
+// translated keys are reviewed, except values that already have a current
+// assessment unless "force" is set; without options both are done.
+func (h *QualityHandler) QueueReview(c echo.Context) error {
+	projectID := c.Param("id")
+	data, err := loadProjectData(h.db, projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	// Reviews cost money, so only the owner and reviewers can start them
+	role := requestRole(c, h.db, data.Project)
+	if role != models.RoleOwner && role != models.RoleReviewer {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only owners and reviewers can run quality reviews"})
+	}
+
+	var params qualityReviewParams
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&params); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		}
+	}
+	if !params.Score && !params.BackTranslate {
+		params.Score, params.BackTranslate = true, true
+	}
+	if _, err := ai.QualityTranslatorName(data.Project.MTSettings.ForLanguage(data.TargetFile.LanguageCode)); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	author := requestAuthor(c, h.db, projectID)
+	params.AuthorType, params.AuthorID = author.Type, author.ID
+	encoded, _ := json.Marshal(params)
+	job := &models.Job{
+		ID:        generateID(),
+		Type:      models.JobQualityReview,
+		ProjectID: projectID,
+		Owner:     models.OwnerID(session.GetSessionToken(c)),
+		Params:    encoded,
+	}
+	if err := h.queue.Enqueue(job); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to queue quality review"})
+	}
+	return renderJob(c, job, http.StatusAccepted)
+}
+
+// GetAssessments handles GET /api/project/:id/quality
+// Returns the quality assessments of the current translations by key, only
+// the low-confidence ones with ?low=true.
+func (h *QualityHandler) GetAssessments(c echo.Context) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	lowOnly := c.QueryParam("low") == "true"
+	assessments := make(map[string]models.QualityAssessment)
+	for key := range data.BaseFlat {
+		a := data.quality(key)
+		if a == nil || (lowOnly && !a.IsLowConfidence()) {
+			continue
+		}
+		assessments[key] = *a
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"threshold":   models.LowQualityScore,
+		"assessments": assessments,
+	})
+}
+
+// qualityReview scores and back-translates the translations of a project in
+// batches with its first LLM provider. Each batch is stored as it completes.
+func qualityReview(ctx context.Context, db *database.DB, job *models.Job, progress jobs.Progress) (interface{}, error) {
+	var params qualityReviewParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, err
+	}
+
+	data, err := loadProjectData(db, job.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	keys := params.Keys
+	reviewed := func(string) bool { return false }
+	if len(keys) == 0 {
+		for k := range data.BaseFlat {
+			keys = append(keys, k)
+		}
+		if !params.Force {
+			// Values are not paid for again while their assessment covers what was asked
+			reviewed = func(k string) bool {
+				a := data.quality(k)
+				return a != nil && (!params.Score || a.Score != nil) && (!params.BackTranslate || a.BackTranslation != "")
+			}
+		}
+	}
+	segments := make(map[string]string) // Key -> text used to size batches
+	skipped := 0
+	for _, k := range keys {
+		source, target := data.BaseFlat[k], data.TargetFlat[k]
+		if source == "" || target == "" || data.Meta[k].DoNotTranslate {
+			continue
+		}
+		if reviewed(k) {
+			skipped++
+			continue
+		}
+		segments[k] = source + "\n" + target
+	}
+
+	batches := ai.SplitBatches(segments, ai.BatchTokens())
+	result := &qualityReviewResult{
+		Batches:       len(batches),
+		Skipped:       skipped,
+		Unscored:      []string{},
+		LowConfidence: []string{},
+		Translators:   []string{},
+		Errors:        []string{},
+	}
+	progress(0, len(segments))
+
+	providers := data.Project.MTSettings.ForLanguage(data.TargetFile.LanguageCode)
+	account := &usageAccount{
+		db:        db,
+		projectID: job.ProjectID,
+		jobID:     job.ID,
+		author:    changeAuthor{Type: params.AuthorType, ID: params.AuthorID},
+		budget:    data.Project.MTSettings.MonthlyBudget,
+	}
+	done := 0
+	for _, batch := range batches {
+		if ctx.Err() != nil {
+			result.Errors = append(result.Errors, ctx.Err().Error())
+			break
+		}
+		req := ai.QualityRequest{
+			SourceLang:    data.BaseFile.LanguageCode,
+			TargetLang:    data.TargetFile.LanguageCode,
+			Segments:      make(map[string]ai.Segment, len(batch)),
+			Style:         data.Project.StyleGuide,
+			Score:         params.Score,
+			BackTranslate: params.BackTranslate,
+		}
+		for k := range batch {
+			req.Segments[k] = ai.Segment{Source: data.BaseFlat[k], Target: data.TargetFlat[k]}
+		}
+
+		done += len(batch)
+		r, err := account.assess(ctx, providers, req)
+		if err != nil {
+			log.Errorf("Quality review batch failed: %v", err)
+			result.Failed++
+			result.Errors = append(result.Errors, err.Error())
+			progress(done, len(segments))
+			continue
+		}
+		if len(result.Translators) == 0 || result.Translators[len(result.Translators)-1] != r.Translator {
+			result.Translators = append(result.Translators, r.Translator)
+		}
+
+		now := time.Now()
+		assessments := make([]models.QualityAssessment, 0, len(r.Assessments))
+		unscored := 0
+		for k := range batch {
+			a, ok := r.Assessments[k]
+			if params.Score && (!ok || a.Score == nil) {
+				// A missing score must not look like a good one
+				result.Unscored = append(result.Unscored, k)
+				unscored++
+				if !ok || a.BackTranslation == "" {
+					continue
+				}
+				// The back-translation is still stored, without issues
+				a.Score, a.Issues = nil, nil
+			}
+			if !ok {
+				continue
+			}
+			assessment := models.QualityAssessment{
+				FileID:          data.TargetFile.ID,
+				Key:             k,
+				TargetHash:      jsontools.SourceHash(data.TargetFlat[k]),
+				Score:           a.Score,
+				Issues:          a.Issues,
+				BackTranslation: a.BackTranslation,
+				Translator:      r.Translator,
+				CreatedAt:       now,
+			}
+			if assessment.Issues == nil {
+				assessment.Issues = []models.QualityIssue{}
+			}
+			if assessment.IsLowConfidence() {
+				result.LowConfidence = append(result.LowConfidence, k)
+			}
+			assessments = append(assessments, assessment)
+		}
+		if unscored > 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s returned no score for %d keys", r.Translator, unscored))
+		}
+		if err := db.SaveQualityAssessments(assessments); err != nil {
+			return nil, fmt.Errorf("failed to save quality assessments: %w", err)
+		}
+		result.Assessed += len(assessments)
+
+		assessed := make([]string, 0, len(assessments))
+		for _, a := range assessments {
+			assessed = append(assessed, a.Key)
+		}
+		sort.Strings(assessed)
+		events.Publish(job.ProjectID, events.Event{Type: events.TypeQuality, Data: events.KeysEvent{
+			Language:   data.TargetFile.LanguageCode,
+			Keys:       assessed,
+			AuthorType: models.AuthorAI,
+			AuthorID:   r.Translator,
+		}})
+		progress(done, len(segments))
+	}
+	sort.Strings(result.LowConfidence)
+	sort.Strings(result.Unscored)
+
+	if result.Failed > 0 && result.Assessed == 0 && ctx.Err() == nil {
+		return nil, fmt.Errorf("quality review failed: %s", strings.Join(result.Errors, "; "))
+	}
+	return result, nil
+}
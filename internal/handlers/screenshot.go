@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+
+	"templui/internal/database"
+	"templui/internal/models"
+	"templui/internal/session"
+	"templui/internal/storage"
+	"templui/ui/pages"
+)
+
+// maxScreenshotSize limits uploaded screenshots to 10 MB
+const maxScreenshotSize = 10 << 20
+
+var screenshotExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type ScreenshotHandler struct {
+	db    *database.DB
+	store storage.Storage
+}
+
+func NewScreenshotHandler(db *database.DB, store storage.Storage) *ScreenshotHandler {
+	return &ScreenshotHandler{db: db, store: store}
+}
+
+// Screenshots handles GET /project/:id/screenshots - shows screenshots with key hotspots
+func (h *ScreenshotHandler) Screenshots(c echo.Context) error {
+	projectID := c.Param("id")
+	highlightKey := c.QueryParam("key")
+
+	data, err := loadProjectData(h.db, projectID)
+	if err != nil {
+		return c.String(http.StatusNotFound, "Project not found")
+	}
+
+	if data.Project.IsLocked && !isAuthenticated(c, data.Project) {
+		return c.Redirect(http.StatusFound, "/project/"+projectID+"/auth")
+	}
+
+	screenshots, err := h.db.GetScreenshotsByProject(projectID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load screenshots")
+	}
+
+	keys := make([]string, 0, len(data.BaseFlat))
+	for key := range data.BaseFlat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	isOwner := session.GetSessionToken(c) == data.Project.SessionToken
+
+	return render(c, pages.Screenshots(data.Project, screenshots, keys, data.BaseFlat, highlightKey, isOwner))
+}
+
+// ListScreenshots handles GET /api/project/:id/screenshots
+func (h *ScreenshotHandler) ListScreenshots(c echo.Context) error {
+	screenshots, err := h.db.GetScreenshotsByProject(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get screenshots"})
+	}
+	if screenshots == nil {
+		screenshots = []models.Screenshot{}
+	}
+	return c.JSON(http.StatusOK, screenshots)
+}
+
+// UploadScreenshot handles POST /api/project/:id/screenshots (multipart field "file")
+func (h *ScreenshotHandler) UploadScreenshot(c echo.Context) error {
+	projectID := c.Param("id")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	if project.SessionToken != session.GetSessionToken(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing file"})
+	}
+	if fileHeader.Size > maxScreenshotSize {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Screenshot is larger than 10 MB"})
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid file"})
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(io.LimitReader(src, maxScreenshotSize+1))
+	if err != nil || len(content) > maxScreenshotSize {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid file"})
+	}
+
+	contentType := http.DetectContentType(content)
+	ext, ok := screenshotExtensions[contentType]
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Screenshots must be PNG, JPEG, GIF or WebP images"})
+	}
+
+	screenshot := &models.Screenshot{
+		ID:          generateID(),
+		ProjectID:   projectID,
+		Name:        fileHeader.Filename,
+		ContentType: contentType,
+		CreatedAt:   time.Now(),
+		Regions:     []models.ScreenshotRegion{},
+	}
+	if name := c.FormValue("name"); name != "" {
+		screenshot.Name = name
+	}
+	screenshot.StorageKey = path.Join("screenshots", projectID, screenshot.ID+ext)
+
+	// WebP has no decoder in the standard library, so its size stays unknown
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(content)); err == nil {
+		screenshot.Width = cfg.Width
+		screenshot.Height = cfg.Height
+	}
+
+	ctx := c.Request().Context()
+	if err := h.store.Put(ctx, screenshot.StorageKey, bytes.NewReader(content), int64(len(content)), contentType); err != nil {
+		log.Errorf("Failed to store screenshot: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to store screenshot"})
+	}
+
+	if err := h.db.CreateScreenshot(screenshot); err != nil {
+		h.store.Delete(ctx, screenshot.StorageKey)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save screenshot"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		c.Response().Header().Set("HX-Refresh", "true")
+	}
+	return c.JSON(http.StatusCreated, screenshot)
+}
+
+// ScreenshotImage handles GET /api/project/:id/screenshots/:sid/image
+func (h *ScreenshotHandler) ScreenshotImage(c echo.Context) error {
+	screenshot, err := h.db.GetScreenshot(c.Param("sid"))
+	if err != nil || screenshot.ProjectID != c.Param("id") {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Screenshot not found"})
+	}
+
+	rc, err := h.store.Get(c.Request().Context(), screenshot.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Screenshot not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to read screenshot"})
+	}
+	defer rc.Close()
+
+	c.Response().Header().Set("Cache-Control", "private, max-age=86400")
+	return c.Stream(http.StatusOK, screenshot.ContentType, rc)
+}
+
+// DeleteScreenshot handles DELETE /api/project/:id/screenshots/:sid
+func (h *ScreenshotHandler) DeleteScreenshot(c echo.Context) error {
+	screenshot, ok := h.ownedScreenshot(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.db.DeleteScreenshot(screenshot.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete screenshot"})
+	}
+	if err := h.store.Delete(c.Request().Context(), screenshot.StorageKey); err != nil {
+		log.Warnf("Failed to delete screenshot object %s: %v", screenshot.StorageKey, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+// CreateRegionRequest represents a rectangle linked to a key, in fractions of the image size
+type CreateRegionRequest struct {
+	Key    string  `json:"key"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// CreateRegion handles POST /api/project/:id/screenshots/:sid/regions
+func (h *ScreenshotHandler) CreateRegion(c echo.Context) error {
+	screenshot, ok := h.ownedScreenshot(c)
+	if !ok {
+		return nil
+	}
+
+	var req CreateRegionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	data, err := loadProjectData(h.db, screenshot.ProjectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if _, ok := data.BaseFlat[req.Key]; !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown key"})
+	}
+	if req.X < 0 || req.Y < 0 || req.Width <= 0 || req.Height <= 0 || req.X+req.Width > 1.0001 || req.Y+req.Height > 1.0001 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Region must lie within the image (coordinates are fractions 0..1)"})
+	}
+
+	region := &models.ScreenshotRegion{
+		ID:           generateID(),
+		ScreenshotID: screenshot.ID,
+		ProjectID:    screenshot.ProjectID,
+		Key:          req.Key,
+		X:            req.X,
+		Y:            req.Y,
+		Width:        req.Width,
+		Height:       req.Height,
+		CreatedAt:    time.Now(),
+	}
+	if err := h.db.CreateScreenshotRegion(region); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save region"})
+	}
+
+	return c.JSON(http.StatusCreated, region)
+}
+
+// DeleteRegion handles DELETE /api/project/:id/screenshots/:sid/regions/:rid
+func (h *ScreenshotHandler) DeleteRegion(c echo.Context) error {
+	screenshot, ok := h.ownedScreenshot(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.db.DeleteScreenshotRegion(screenshot.ID, c.Param("rid")); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete region"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+// ownedScreenshot loads the screenshot from the route and checks that the caller owns
+// its project. On failure the error response has already been written.
+func (h *ScreenshotHandler) ownedScreenshot(c echo.Context) (*models.Screenshot, bool) {
+	project, err := h.db.GetProject(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+		return nil, false
+	}
+	if project.SessionToken != session.GetSessionToken(c) {
+		c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	screenshot, err := h.db.GetScreenshot(c.Param("sid"))
+	if err != nil || screenshot.ProjectID != project.ID {
+		c.JSON(http.StatusNotFound, map[string]string{"error": "Screenshot not found"})
+		return nil, false
+	}
+	return screenshot, true
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+
+	"templui/internal/database"
+	"templui/ui/pages"
+)
+
+// maxSearchResults limits the number of hits a concordance search returns
+const maxSearchResults = 200
+
+type SearchHandler struct {
+	db *database.DB
+}
+
+func NewSearchHandler(db *database.DB) *SearchHandler {
+	return &SearchHandler{db: db}
+}
+
+// searchQuery reads the concordance search parameters of a request
+func searchQuery(c echo.Context) database.SearchQuery {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	return database.SearchQuery{
+		Text:       strings.TrimSpace(c.QueryParam("q")),
+		SourceLang: c.QueryParam("source"),
+		TargetLang: c.QueryParam("target"),
+		Scope:      c.QueryParam("in"),
+		Limit:      min(limit, maxSearchResults),
+	}
+}
+
+// SearchPage handles GET /search
+func (h *SearchHandler) SearchPage(c echo.Context) error {
+	q := searchQuery(c)
+	return render(c, pages.Search(q.Text, q.SourceLang, q.TargetLang, q.Scope))
+}
+
+// Search handles GET /api/search?q=
+// Searches base values and translations of all projects the caller can access,
+// optionally filtered by ?source= and ?target= language and limited to one
+// side with ?in=source|target.
+func (h *SearchHandler) Search(c echo.Context) error {
+	q := searchQuery(c)
+	if q.Scope != "" && q.Scope != "source" && q.Scope != "target" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "in must be source or target"})
+	}
+
+	projectIDs, err := accessibleProjects(c, h.db)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get projects"})
+	}
+	if projectID := c.QueryParam("project"); projectID != "" {
+		var filtered []string
+		for _, id := range projectIDs {
+			if id == projectID {
+				filtered = append(filtered, id)
+			}
+		}
+		projectIDs = filtered
+	}
+	q.ProjectIDs = projectIDs
+
+	if err := h.db.RefreshSearchIndex(projectIDs); err != nil {
+		log.Errorf("failed to refresh search index: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update search index"})
+	}
+	hits, err := h.db.Search(q)
+	if err != nil {
+		log.Errorf("search failed: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Search failed"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return render(c, pages.SearchResults(q.Text, hits))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"query":   q.Text,
+		"results": hits,
+	})
+}
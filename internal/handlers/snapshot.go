@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/jsontools"
+	"templui/internal/models"
+	"templui/internal/session"
+	"templui/ui/pages"
+)
+
+type SnapshotHandler struct {
+	db *database.DB
+}
+
+func NewSnapshotHandler(db *database.DB) *SnapshotHandler {
+	return &SnapshotHandler{db: db}
+}
+
+// ListSnapshots handles GET /api/project/:id/snapshots
+func (h *SnapshotHandler) ListSnapshots(c echo.Context) error {
+	projectID := c.Param("id")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	snapshots, err := h.db.ListSnapshots(projectID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get snapshots"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		isOwner := session.GetSessionToken(c) == project.SessionToken
+		return render(c, pages.SnapshotList(projectID, snapshots, isOwner))
+	}
+	return c.JSON(http.StatusOK, snapshots)
+}
+
+// CreateSnapshotRequest represents the request body for cutting a snapshot
+type CreateSnapshotRequest struct {
+	Name        string `json:"name" form:"name"`
+	Description string `json:"description" form:"description"`
+}
+
+// CreateSnapshot handles POST /api/project/:id/snapshots
+func (h *SnapshotHandler) CreateSnapshot(c echo.Context) error {
+	projectID := c.Param("id")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	if project.SessionToken != session.GetSessionToken(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+	}
+
+	var req CreateSnapshotRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || req.Name == "current" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "A snapshot name is required"})
+	}
+	if _, err := h.db.GetSnapshot(projectID, req.Name); err == nil {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "A snapshot with this name already exists"})
+	}
+
+	files, err := h.db.GetFilesByProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get files"})
+	}
+
+	snapshot := &models.Snapshot{
+		ID:          generateID(),
+		ProjectID:   projectID,
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+		CreatedAt:   time.Now(),
+	}
+	for _, f := range files {
+		snapshot.Files = append(snapshot.Files, models.SnapshotFile{
+			FileType:     f.FileType,
+			LanguageCode: f.LanguageCode,
+			Content:      f.Content,
+		})
+	}
+
+	if err := h.db.CreateSnapshot(snapshot); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create snapshot"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		snapshots, _ := h.db.ListSnapshots(projectID)
+		return render(c, pages.SnapshotList(projectID, snapshots, true))
+	}
+	return c.JSON(http.StatusCreated, snapshot)
+}
+
+// snapshotFileKey identifies a file within a snapshot, e.g. "target:de". The
+// base and target file can share a language code.
+func snapshotFileKey(fileType, languageCode string) string {
+	return fileType + ":" + languageCode
+}
+
+// snapshotVersion returns the flattened files of a snapshot by snapshotFileKey,
+// or of the working copy when ref is "current"
+func (h *SnapshotHandler) snapshotVersion(projectID, ref string) (map[string]map[string]string, error) {
+	versions := make(map[string]map[string]string)
+	if ref == "current" {
+		files, err := h.db.GetFilesByProject(projectID)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			data, _ := jsontools.ParseJSON([]byte(f.Content))
+			versions[snapshotFileKey(f.FileType, f.LanguageCode)] = jsontools.FlattenJSON(data, "")
+		}
+		return versions, nil
+	}
+
+	snapshot, err := h.db.GetSnapshot(projectID, ref)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range snapshot.Files {
+		data, _ := jsontools.ParseJSON([]byte(f.Content))
+		versions[snapshotFileKey(f.FileType, f.LanguageCode)] = jsontools.FlattenJSON(data, "")
+	}
+	return versions, nil
+}
+
+// DiffSnapshots handles GET /api/project/:id/snapshots/diff?from=&to=
+// Either side may be a snapshot ID or name; "to" defaults to the working copy ("current").
+func (h *SnapshotHandler) DiffSnapshots(c echo.Context) error {
+	projectID := c.Param("id")
+	from := c.QueryParam("from")
+	to := c.QueryParam("to")
+	if to == "" {
+		to = "current"
+	}
+	if from == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "from is required"})
+	}
+
+	fromVersion, err := h.snapshotVersion(projectID, from)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Snapshot not found: " + from})
+	}
+	toVersion, err := h.snapshotVersion(projectID, to)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Snapshot not found: " + to})
+	}
+
+	files := make(map[string]jsontools.ChangeSet)
+	for file, flat := range toVersion {
+		files[file] = jsontools.DiffVersions(fromVersion[file], flat)
+	}
+	for file, flat := range fromVersion {
+		if _, ok := toVersion[file]; !ok {
+			files[file] = jsontools.DiffVersions(flat, map[string]string{})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"from":  from,
+		"to":    to,
+		"files": files,
+	})
+}
+
+// RestoreSnapshot handles POST /api/project/:id/snapshots/:sid/restore
+// Every file in the snapshot that still exists in the project is overwritten
+// with the snapshot content in one transaction. Target changes are recorded in
+// the history.
+func (h *SnapshotHandler) RestoreSnapshot(c echo.Context) error {
+	projectID := c.Param("id")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	if project.SessionToken != session.GetSessionToken(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+	}
+
+	snapshot, err := h.db.GetSnapshot(projectID, c.Param("sid"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Snapshot not found"})
+	}
+
+	files, err := h.db.GetFilesByProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get files"})
+	}
+
+	// Restored translations were made from the snapshot's base file
+	var base map[string]string
+	for _, f := range snapshot.Files {
+		if f.FileType == "base" {
+			baseData, _ := jsontools.ParseJSON([]byte(f.Content))
+			base = jsontools.FlattenJSON(baseData, "")
+		}
+	}
+
+	author := requestAuthor(c, h.db, projectID)
+	restored := []string{}
+	var updates []database.FileUpdate
+	for _, sf := range snapshot.Files {
+		for i := range files {
+			file := &files[i]
+			if file.LanguageCode != sf.LanguageCode || file.FileType != sf.FileType || file.Content == sf.Content {
+				continue
+			}
+
+			update := database.FileUpdate{ID: file.ID, Content: sf.Content}
+			if file.FileType != "base" {
+				currentData, _ := jsontools.ParseJSON([]byte(file.Content))
+				snapshotData, _ := jsontools.ParseJSON([]byte(sf.Content))
+				before := jsontools.FlattenJSON(currentData, "")
+				after := jsontools.FlattenJSON(snapshotData, "")
+				// Keys missing from the snapshot are recorded as cleared
+				history := maps.Clone(after)
+				for key := range before {
+					if _, ok := history[key]; !ok {
+						history[key] = ""
+					}
+				}
+				update.Revisions = buildRevisions(file, base, before, history, author, models.SourceRestore)
+			}
+			updates = append(updates, update)
+			restored = append(restored, snapshotFileKey(sf.FileType, sf.LanguageCode))
+		}
+	}
+	if err := h.db.UpdateFiles(updates); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to restore snapshot"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		c.Response().Header().Set("HX-Refresh", "true")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"snapshot": snapshot.Name, "restored": restored})
+}
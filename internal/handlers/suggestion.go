@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/jsontools"
+	"templui/internal/models"
+	"templui/ui/pages"
+)
+
+type SuggestionHandler struct {
+	db *database.DB
+}
+
+func NewSuggestionHandler(db *database.DB) *SuggestionHandler {
+	return &SuggestionHandler{db: db}
+}
+
+// renderSuggestions renders the suggestion panel of a key, or returns it as JSON
+func (h *SuggestionHandler) renderSuggestions(c echo.Context, data *projectData, key string, status int, errorMessage string) error {
+	suggestions, err := h.db.GetSuggestions(data.TargetFile.ID, key, sessionFingerprint(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get suggestions"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		role := requestRole(c, h.db, data.Project)
+		return render(c, pages.SuggestionList(data.Project.ID, key, suggestions, role, sessionFingerprint(c), canSuggest(c, h.db, data.Project), errorMessage))
+	}
+	if errorMessage != "" {
+		return c.JSON(status, map[string]string{"error": errorMessage})
+	}
+	return c.JSON(status, suggestions)
+}
+
+// ListSuggestions handles GET /api/project/:id/suggestions?key=
+func (h *SuggestionHandler) ListSuggestions(c echo.Context) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if data.Project.IsLocked && !isAuthenticated(c, data.Project) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Project is locked"})
+	}
+	return h.renderSuggestions(c, data, c.QueryParam("key"), http.StatusOK, "")
+}
+
+// CreateSuggestionRequest represents the request body for suggesting a translation
+type CreateSuggestionRequest struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// CreateSuggestion handles POST /api/project/:id/suggestions
+// Accepts a JSON body, or from the editor ?key= with the value in the
+// "suggestion:<key>" form field.
+func (h *SuggestionHandler) CreateSuggestion(c echo.Context) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if !canSuggest(c, h.db, data.Project) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Suggesting translations requires a share link"})
+	}
+
+	var req CreateSuggestionRequest
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		}
+	} else {
+		req.Key = c.QueryParam("key")
+		req.Value = c.FormValue("suggestion:" + req.Key)
+	}
+	req.Value = strings.TrimSpace(req.Value)
+
+	baseValue, ok := data.BaseFlat[req.Key]
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Key not found"})
+	}
+	if req.Value == "" {
+		return h.renderSuggestions(c, data, req.Key, http.StatusBadRequest, "A suggestion cannot be empty")
+	}
+	// Suggestions must pass the same structural checks as edits so they can be accepted
+	if err := jsontools.ValidateValue(baseValue, req.Value); err != nil {
+		return h.renderSuggestions(c, data, req.Key, http.StatusBadRequest, err.Error())
+	}
+
+	suggestion := &models.Suggestion{
+		ID:         generateID(),
+		ProjectID:  data.Project.ID,
+		FileID:     data.TargetFile.ID,
+		Key:        req.Key,
+		Value:      req.Value,
+		AuthorType: models.AuthorSession,
+		AuthorID:   sessionFingerprint(c),
+		Status:     models.SuggestionOpen,
+		CreatedAt:  time.Now(),
+	}
+	if err := h.db.CreateSuggestion(suggestion); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save suggestion"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return h.renderSuggestions(c, data, req.Key, http.StatusCreated, "")
+	}
+	return c.JSON(http.StatusCreated, suggestion)
+}
+
+// loadSuggestion loads the project and an open suggestion belonging to its target file
+func (h *SuggestionHandler) loadSuggestion(c echo.Context) (*projectData, *models.Suggestion, int, string) {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return nil, nil, http.StatusNotFound, err.Error()
+	}
+	suggestion, err := h.db.GetSuggestion(c.Param("sid"), sessionFingerprint(c))
+	if err != nil || suggestion.FileID != data.TargetFile.ID {
+		return nil, nil, http.StatusNotFound, "Suggestion not found"
+	}
+	if suggestion.Status != models.SuggestionOpen {
+		return nil, nil, http.StatusConflict, "Suggestion is already " + suggestion.Status
+	}
+	return data, suggestion, http.StatusOK, ""
+}
+
+// VoteSuggestion handles POST /api/project/:id/suggestions/:sid/vote
+func (h *SuggestionHandler) VoteSuggestion(c echo.Context) error {
+	data, suggestion, status, message := h.loadSuggestion(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !canSuggest(c, h.db, data.Project) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Voting requires a share link"})
+	}
+	if suggestion.AuthorID == sessionFingerprint(c) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You cannot vote for your own suggestion"})
+	}
+
+	if err := h.db.VoteSuggestion(suggestion.ID, sessionFingerprint(c)); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to record vote"})
+	}
+	return h.renderSuggestions(c, data, suggestion.Key, http.StatusOK, "")
+}
+
+// AcceptSuggestion handles POST /api/project/:id/suggestions/:sid/accept
+// The value goes through the same validation as a direct edit and is recorded
+// in the history under the contributor who suggested it.
+func (h *SuggestionHandler) AcceptSuggestion(c echo.Context) error {
+	data, suggestion, status, message := h.loadSuggestion(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if role := requestRole(c, h.db, data.Project); role != models.RoleOwner && role != models.RoleReviewer {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only owners and reviewers can accept suggestions"})
+	}
+
+	author := changeAuthor{Type: suggestion.AuthorType, ID: suggestion.AuthorID}
+	values := map[string]string{suggestion.Key: suggestion.Value}
+	_, failedKey, message, err := applyTranslations(h.db, data, values, author, models.SourceSuggestion)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update file"})
+	}
+	if failedKey != "" {
+		return h.renderSuggestions(c, data, suggestion.Key, http.StatusUnprocessableEntity, message)
+	}
+
+	if err := h.db.SetSuggestionStatus(suggestion.ID, models.SuggestionAccepted); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update suggestion"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		data.Suggested[suggestion.Key]--
+		return renderField(c, h.db, data, suggestion.Key, "")
+	}
+	return c.JSON(http.StatusOK, map[string]string{"key": suggestion.Key, "value": suggestion.Value})
+}
+
+// DismissSuggestion handles POST /api/project/:id/suggestions/:sid/dismiss
+func (h *SuggestionHandler) DismissSuggestion(c echo.Context) error {
+	data, suggestion, status, message := h.loadSuggestion(c)
+	if message != "" {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if role := requestRole(c, h.db, data.Project); role != models.RoleOwner && role != models.RoleReviewer {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only owners and reviewers can dismiss suggestions"})
+	}
+
+	if err := h.db.SetSuggestionStatus(suggestion.ID, models.SuggestionDismissed); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update suggestion"})
+	}
+	return h.renderSuggestions(c, data, suggestion.Key, http.StatusOK, "")
+}
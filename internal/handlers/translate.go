@@ -0,0 +1,608 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+
+	"templui/internal/ai"
+	"templui/internal/database"
+	"templui/internal/events"
+	"templui/internal/jobs"
+	"templui/internal/jsontools"
+	"templui/internal/models"
+	"templui/internal/qa"
+)
+
+// translationRun is the outcome of a chunked machine translation run
+type translationRun struct {
+	BatchID      string            `json:"batch_id"`
+	Batches      int               `json:"batches"`
+	Failed       int               `json:"failed_batches"`
+	Translated   int               `json:"translated"`
+	Repaired     int               `json:"repaired"`     // Values fixed by a repair prompt
+	Retranslated int               `json:"retranslated"` // Existing values replaced by a new translation
+	Cached       int               `json:"cached"`       // Values reused from the machine translation cache
+	CacheSaved   float64           `json:"cache_saved"`  // Estimated cost in USD the cache hits saved
+	Rejected     map[string]string `json:"rejected"`     // Key -> reason the value was not saved
+	NeedsReview  map[string]string `json:"needs_review"` // Key -> problem of a value saved for review
+	Dropped      []string          `json:"dropped"`      // Keys the provider did not return
+	Unexpected   []string          `json:"unexpected"`   // Keys the provider returned but were not requested
+	Translators  []string          `json:"translators"`
+	Errors       []string          `json:"errors"`
+
+	previous map[string]string // Values the run replaces by key, "" for missing keys
+}
+
+// newTranslationRun creates an empty run result
+func newTranslationRun(batchID string, batches int) *translationRun {
+	return &translationRun{
+		BatchID:     batchID,
+		Batches:     batches,
+		Rejected:    map[string]string{},
+		NeedsReview: map[string]string{},
+		Dropped:     []string{},
+		Unexpected:  []string{},
+		Translators: []string{},
+		Errors:      []string{},
+	}
+}
+
+// batchResult is the provider response for one batch
+type batchResult struct {
+	texts        map[string]string
+	translations map[string]string
+	translator   string
+	prompts      map[string]string // Key -> prompt that produced the translation
+	repaired     int
+	err          error
+}
+
+// mtChecks are the QA rules machine translations must pass, besides valid
+// placeholders and markup and the project's error-level rules, to be saved as
+// final. Like those, they only apply while the project has them turned on.
+var mtChecks = []string{"max_length", "glossary", "do_not_translate"}
+
+// mtValidator checks machine translations against the source, the key
+// metadata, the glossary and the QA rules of a project
+type mtValidator struct {
+	base   map[string]string
+	meta   map[string]models.KeyMetadata
+	terms  []qa.Term
+	checks []qa.Check
+}
+
+// newMTValidator creates a validator for the project's current data
+func newMTValidator(data *projectData) *mtValidator {
+	cfg := qa.ParseConfig(data.Project.QARules)
+	var checks []qa.Check
+	for _, check := range qa.Checks() {
+		switch severity := cfg.Severity(check); {
+		case severity == qa.SeverityError:
+			checks = append(checks, check)
+		case severity != qa.SeverityOff && slices.Contains(mtChecks, check.ID()):
+			checks = append(checks, check)
+		}
+	}
+	return &mtValidator{
+		base:   data.BaseFlat,
+		meta:   data.Meta,
+		terms:  data.Terms,
+		checks: checks,
+	}
+}
+
+// check returns the problem of a translation, or "" when it is valid. Fatal
+// problems (broken placeholders or markup) mean the value must not be saved.
+func (v *mtValidator) check(key, value string) (problem string, fatal bool) {
+	if err := jsontools.ValidateValue(v.base[key], value); err != nil {
+		return err.Error(), true
+	}
+	entry := qa.Entry{
+		Key:            key,
+		Base:           v.base[key],
+		Target:         value,
+		MaxLength:      v.meta[key].MaxLength,
+		DoNotTranslate: v.meta[key].DoNotTranslate,
+		Terms:          v.terms,
+	}
+	var problems []string
+	for _, check := range v.checks {
+		for _, f := range check.Run([]qa.Entry{entry}) {
+			problems = append(problems, f.Message)
+		}
+	}
+	return strings.Join(problems, "; "), false
+}
+
+// translateBatch translates one batch. When an LLM provider translated it,
+// translations that fail validation are sent back to that provider with the
+// problem, up to ai.RepairAttempts times; other providers cannot take the
+// problem into account.
+func translateBatch(ctx context.Context, account *usageAccount, providers []models.MTProvider, req ai.Request, v *mtValidator) batchResult {
+	result, err := account.translate(ctx, providers, req)
+	if err != nil {
+		return batchResult{texts: req.Texts, err: err}
+	}
+	r := batchResult{
+		texts:        req.Texts,
+		translations: result.Translations,
+		translator:   result.Translator,
+		prompts:      make(map[string]string),
+	}
+	for k := range r.translations {
+		r.prompts[k] = result.Prompt
+	}
+	// Only an ai.Prompter has a prompt to send the problem with
+	if result.Prompt == "" {
+		return r
+	}
+	repairProviders := []models.MTProvider{result.Provider}
+
+	for attempt := 0; attempt < ai.RepairAttempts(); attempt++ {
+		repairs := make(map[string]ai.Repair)
+		texts := make(map[string]string)
+		for k, value := range r.translations {
+			if _, ok := req.Texts[k]; !ok || value == "" {
+				continue
+			}
+			if problem, _ := v.check(k, value); problem != "" {
+				repairs[k] = ai.Repair{Previous: value, Problem: problem}
+				texts[k] = req.Texts[k]
+			}
+		}
+		if len(repairs) == 0 {
+			break
+		}
+
+		repairReq := req
+		repairReq.Texts = texts
+		repairReq.Repairs = repairs
+		repaired, err := account.translate(ctx, repairProviders, repairReq)
+		if err != nil {
+			log.Warnf("Repair of %d translations failed: %v", len(repairs), err)
+			break
+		}
+		for k, value := range repaired.Translations {
+			if _, ok := repairs[k]; !ok || value == "" {
+				continue
+			}
+			if problem, _ := v.check(k, value); problem == "" {
+				r.repaired++
+			}
+			r.translations[k] = value
+			r.prompts[k] = repaired.Prompt
+		}
+	}
+	return r
+}
+
+// translateInBatches translates the request texts in batches that fit the
+// token budget, several batches at a time. Texts found in the cache are saved
+// first without calling the provider. Each batch is saved as soon as it
+// completes, so a failed or cancelled run keeps its progress. All batches are
+// recorded under one batch ID so the run can be undone as a whole.
+func translateInBatches(ctx context.Context, db *database.DB, fileID string, v *mtValidator, account *usageAccount, cache *mtCache, providers []models.MTProvider, req ai.Request, previous map[string]string, progress func(done int)) (*translationRun, error) {
+	// Re-translations skip the cache, it would likely return the value being replaced
+	lookupReq := req
+	lookupReq.Texts = make(map[string]string, len(req.Texts))
+	for k, text := range req.Texts {
+		if previous[k] == "" {
+			lookupReq.Texts[k] = text
+		}
+	}
+	hits := cache.lookup(lookupReq)
+	misses := make(map[string]string, len(req.Texts)-len(hits))
+	for k, text := range req.Texts {
+		if _, ok := hits[k]; !ok {
+			misses[k] = text
+		}
+	}
+	batches := ai.SplitBatches(misses, ai.BatchTokens())
+	run := newTranslationRun(account.batchID, len(batches))
+	run.previous = previous
+
+	translators := make(map[string]bool)
+	done := 0
+	if len(hits) > 0 {
+		hitReq := req
+		hitReq.Texts = make(map[string]string, len(hits))
+		for k := range hits {
+			hitReq.Texts[k] = req.Texts[k]
+		}
+		r := batchResult{texts: hitReq.Texts, translations: hits, translator: cache.translator}
+		translators[r.translator] = true
+		run.Translators = append(run.Translators, r.translator)
+		if err := saveBatch(db, fileID, v, r, run); err != nil {
+			return run, err
+		}
+		run.Cached = len(hits)
+		run.CacheSaved = ai.EstimateUsage(cache.translator, hitReq).Cost(cache.translator)
+		done += len(hits)
+		progress(done)
+	}
+	req.Texts = misses
+
+	jobs := make(chan map[string]string)
+	results := make(chan batchResult)
+	var wg sync.WaitGroup
+	for i := 0; i < min(ai.Concurrency(), len(batches)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for texts := range jobs {
+				batchReq := req
+				batchReq.Texts = texts
+				results <- translateBatch(ctx, account, providers, batchReq, v)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, texts := range batches {
+			select {
+			case jobs <- texts:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Batches are saved one at a time as they arrive
+	var saveErr error
+	for r := range results {
+		done += len(r.texts)
+		switch {
+		case r.err != nil:
+			log.Errorf("Translation batch failed: %v", r.err)
+			run.Failed++
+			run.Errors = append(run.Errors, r.err.Error())
+		case saveErr == nil:
+			if !translators[r.translator] {
+				translators[r.translator] = true
+				run.Translators = append(run.Translators, r.translator)
+			}
+			cache.store(req, r, v)
+			saveErr = saveBatch(db, fileID, v, r, run)
+		}
+		progress(done)
+	}
+	if ctx.Err() != nil {
+		run.Errors = append(run.Errors, ctx.Err().Error())
+	}
+
+	sort.Strings(run.Dropped)
+	sort.Strings(run.Unexpected)
+	return run, saveErr
+}
+
+// saveBatch validates the translations of a batch and saves them into the
+// current target file. Values with broken placeholders or markup are rejected,
+// values that fail other checks are saved with the needs review status. Keys
+// filled or changed by someone else in the meantime are kept.
+func saveBatch(db *database.DB, fileID string, v *mtValidator, r batchResult, run *translationRun) error {
+	file, err := db.GetFile(fileID)
+	if err != nil {
+		return err
+	}
+	data, _ := jsontools.ParseJSON([]byte(file.Content))
+	targetFlat := jsontools.FlattenJSON(data, "")
+	before := maps.Clone(targetFlat)
+
+	review := make(map[string]string)
+	for k, value := range r.translations {
+		if _, ok := r.texts[k]; !ok {
+			run.Unexpected = append(run.Unexpected, k)
+			continue
+		}
+		if value == "" {
+			run.Dropped = append(run.Dropped, k)
+			continue
+		}
+		problem, fatal := v.check(k, value)
+		if fatal {
+			run.Rejected[k] = problem
+			events.Publish(file.ProjectID, events.Event{Type: events.TypeValidation, Data: events.ValidationEvent{Key: k, Error: problem}})
+			continue
+		}
+		if targetFlat[k] != run.previous[k] {
+			continue
+		}
+		targetFlat[k] = value
+		if problem != "" {
+			review[k] = problem
+		}
+	}
+	for k := range r.texts {
+		if _, ok := r.translations[k]; !ok {
+			run.Dropped = append(run.Dropped, k)
+		}
+	}
+
+	promptIDs, err := recordPrompts(db, file.ProjectID, run.BatchID, r)
+	if err != nil {
+		return err
+	}
+
+	author := changeAuthor{Type: models.AuthorAI, ID: r.translator}
+	revisions, err := saveTargetInBatch(db, file, v.base, before, targetFlat, author, models.SourceAI, run.BatchID, promptIDs)
+	if err != nil {
+		return err
+	}
+	run.Translated += len(revisions)
+	for _, rev := range revisions {
+		if run.previous[rev.Key] != "" {
+			run.Retranslated++
+		}
+	}
+	run.Repaired += r.repaired
+
+	// Values that still fail validation need a human to look at them
+	for _, rev := range revisions {
+		problem, ok := review[rev.Key]
+		if !ok {
+			continue
+		}
+		if err := db.SetStatus(fileID, rev.Key, models.StatusNeedsReview, r.translator); err != nil {
+			return err
+		}
+		run.NeedsReview[rev.Key] = problem
+		events.Publish(file.ProjectID, events.Event{Type: events.TypeValidation, Data: events.ValidationEvent{Key: rev.Key, Error: problem}})
+	}
+	return nil
+}
+
+// recordPrompts stores the distinct prompts of a batch for auditing and
+// returns the prompt ID of each translated key
+func recordPrompts(db *database.DB, projectID, batchID string, r batchResult) (map[string]string, error) {
+	ids := make(map[string]string)       // Prompt -> ID
+	promptIDs := make(map[string]string) // Key -> prompt ID
+	var prompts []models.MTPrompt
+	for k, prompt := range r.prompts {
+		if prompt == "" {
+			continue
+		}
+		if _, ok := ids[prompt]; !ok {
+			ids[prompt] = generateID()
+			prompts = append(prompts, models.MTPrompt{
+				ID:         ids[prompt],
+				ProjectID:  projectID,
+				BatchID:    batchID,
+				Translator: r.translator,
+				Prompt:     prompt,
+				CreatedAt:  time.Now(),
+			})
+		}
+		promptIDs[k] = ids[prompt]
+	}
+	if err := db.CreatePrompts(prompts); err != nil {
+		return nil, err
+	}
+	return promptIDs, nil
+}
+
+// autoTranslateParams are the parameters of an auto_translate job. Without
+// selectors all missing keys are translated; several selectors must all match.
+type autoTranslateParams struct {
+	AuthorType  string   `json:"author_type"` // Who started the run
+	AuthorID    string   `json:"author_id"`
+	Keys        []string `json:"keys,omitempty" form:"keys"`               // Only these keys
+	Prefix      string   `json:"prefix,omitempty" form:"prefix"`           // Only keys under this namespace, e.g. "errors"
+	View        string   `json:"view,omitempty" form:"view"`               // Only keys of an editor view, e.g. "outdated"
+	Status      string   `json:"status,omitempty" form:"status"`           // Only keys with this workflow status
+	Retranslate bool     `json:"retranslate,omitempty" form:"retranslate"` // Also replace existing values
+	// Also replace approved values; only owners and reviewers may set it
+	IncludeApproved bool `json:"include_approved,omitempty" form:"include_approved"`
+}
+
+// editorViews are the views the keys of an auto_translate job can be limited to
+var editorViews = []string{"", "full", "missing", "outdated", "questions", "low_confidence"}
+
+// scope returns whether a key is selected by the params
+func (p autoTranslateParams) scope(data *projectData) func(key string) bool {
+	var keys, view map[string]bool
+	if len(p.Keys) > 0 {
+		keys = make(map[string]bool, len(p.Keys))
+		for _, k := range p.Keys {
+			keys[k] = true
+		}
+	}
+	if (p.View != "" && p.View != "full") || p.Status != "" {
+		view = make(map[string]bool)
+		for _, k := range data.filterKeys(p.View, p.Status) {
+			view[k] = true
+		}
+	}
+	prefix := strings.TrimSuffix(p.Prefix, ".")
+	return func(key string) bool {
+		if keys != nil && !keys[key] {
+			return false
+		}
+		if view != nil && !view[key] {
+			return false
+		}
+		return prefix == "" || key == prefix || strings.HasPrefix(key, prefix+".")
+	}
+}
+
+// sameRun reports whether the params select the same keys with the same
+// options as other, whoever started either run
+func (p autoTranslateParams) sameRun(other autoTranslateParams) bool {
+	normalize := func(p autoTranslateParams) autoTranslateParams {
+		p.AuthorType, p.AuthorID = "", ""
+		p.Keys = slices.Sorted(slices.Values(p.Keys))
+		p.Prefix = strings.TrimSuffix(p.Prefix, ".")
+		if p.View == "full" {
+			p.View = ""
+		}
+		return p
+	}
+	a, b := normalize(p), normalize(other)
+	return slices.Equal(a.Keys, b.Keys) && a.Prefix == b.Prefix && a.View == b.View && a.Status == b.Status &&
+		a.Retranslate == b.Retranslate && a.IncludeApproved == b.IncludeApproved
+}
+
+// autoTranslateResult is the outcome of an auto_translate job
+type autoTranslateResult struct {
+	*translationRun
+	Status  string `json:"status"` // "success", "partial" or "nothing"
+	Memory  int    `json:"memory"` // Keys filled from the translation memory
+	Copied  int    `json:"copied"` // Do-not-translate keys copied from the base
+	Missing int    `json:"missing"`
+	Locked  int    `json:"approved_kept,omitempty"` // Approved values left alone by a re-translation
+}
+
+// autoTranslate fills the missing translations of a project, or of the keys
+// selected by the job: 100% memory matches first, then do-not-translate keys
+// copied from the base, then the rest with the project's machine translation
+// providers. With retranslate, existing values of the selected keys are
+// translated again; the values they replace stay in the history.
+func autoTranslate(ctx context.Context, db *database.DB, job *models.Job, progress jobs.Progress) (interface{}, error) {
+	var params autoTranslateParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, err
+	}
+	author := changeAuthor{Type: params.AuthorType, ID: params.AuthorID}
+
+	data, err := loadProjectData(db, job.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	baseFile, targetFile := data.BaseFile, data.TargetFile
+	baseFlat, targetFlat, meta := data.BaseFlat, data.TargetFlat, data.Meta
+
+	// Fill 100% translation memory matches first so they are not paid for again
+	inScope := params.scope(data)
+	skip := make(map[string]bool)
+	for k := range baseFlat {
+		skip[k] = meta[k].DoNotTranslate || !inScope(k)
+	}
+	fromMemory, memoryReview, err := fillFromMemory(db, data, skip, author)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pre-translate from memory: %w", err)
+	}
+	before := maps.Clone(targetFlat)
+
+	filled := make(map[string]bool, len(fromMemory))
+	for _, rev := range fromMemory {
+		filled[rev.Key] = true
+	}
+
+	// Identify missing translations, and the existing ones to re-translate.
+	// Do-not-translate keys are copied from the base as is.
+	missing := make(map[string]string)
+	previous := make(map[string]string)
+	copied, locked := 0, 0
+	for k, v := range baseFlat {
+		if !inScope(k) || filled[k] {
+			continue
+		}
+		if targetFlat[k] != "" {
+			switch {
+			case !params.Retranslate || v == "" || meta[k].DoNotTranslate:
+			case data.status(k) == models.StatusApproved && !params.IncludeApproved:
+				// Approved values only change with the explicit consent of a reviewer
+				locked++
+			default:
+				previous[k] = targetFlat[k]
+				missing[k] = v
+			}
+			continue
+		}
+		if meta[k].DoNotTranslate {
+			targetFlat[k] = v
+			copied++
+			continue
+		}
+		missing[k] = v
+	}
+	log.Infof("Found %d missing translations for project %s, %d of them to re-translate", len(missing), job.ProjectID, len(previous))
+
+	batchID := generateID()
+	if len(fromMemory) > 0 {
+		batchID = fromMemory[0].BatchID
+	}
+	result := &autoTranslateResult{
+		translationRun: newTranslationRun(batchID, 0),
+		Status:         "success",
+		Memory:         len(fromMemory),
+		Copied:         copied,
+		Missing:        len(missing),
+		Locked:         locked,
+	}
+	maps.Copy(result.NeedsReview, memoryReview)
+	if len(missing) == 0 && copied == 0 && len(fromMemory) == 0 {
+		result.Status = "nothing"
+		return result, nil
+	}
+	progress(0, len(missing))
+
+	// Do-not-translate copies are saved first, in the same batch as the translations
+	if copied > 0 {
+		if _, err := saveTargetInBatch(db, targetFile, baseFlat, before, targetFlat, author, models.SourceAI, batchID, nil); err != nil {
+			return nil, fmt.Errorf("failed to save translations: %w", err)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	// Existing translations give the model context and approved examples
+	existing := make(map[string]ai.Segment)
+	for k, v := range targetFlat {
+		if _, ok := missing[k]; !ok && v != "" && baseFlat[k] != "" {
+			existing[k] = ai.Segment{Source: baseFlat[k], Target: v, Approved: data.status(k) == models.StatusApproved}
+		}
+	}
+
+	// Translate in batches with the project's providers in fallback order
+	req := ai.Request{
+		SourceLang: baseFile.LanguageCode,
+		TargetLang: targetFile.LanguageCode,
+		Texts:      missing,
+		Meta:       meta,
+		Glossary:   data.Glossary,
+		Style:      data.Project.StyleGuide,
+		Existing:   existing,
+	}
+	providers := data.Project.MTSettings.ForLanguage(targetFile.LanguageCode)
+	account := &usageAccount{
+		db:        db,
+		projectID: job.ProjectID,
+		jobID:     job.ID,
+		batchID:   batchID,
+		author:    author,
+		budget:    data.Project.MTSettings.MonthlyBudget,
+	}
+	cache := newMTCache(db, providers)
+	run, err := translateInBatches(ctx, db, targetFile.ID, newMTValidator(data), account, cache, providers, req, previous, func(done int) {
+		progress(done, len(missing))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save translations: %w", err)
+	}
+	result.translationRun = run
+	maps.Copy(run.NeedsReview, memoryReview)
+	if len(run.Rejected) > 0 || len(run.NeedsReview) > 0 || len(run.Dropped) > 0 || len(run.Unexpected) > 0 {
+		log.Warnf("AI translation for project %s: rejected %v, needs review %v, dropped %v, unexpected %v", job.ProjectID, run.Rejected, run.NeedsReview, run.Dropped, run.Unexpected)
+	}
+	if len(run.Errors) > 0 {
+		if run.Translated == 0 && copied == 0 && len(fromMemory) == 0 && ctx.Err() == nil {
+			return nil, fmt.Errorf("AI Translation failed: %s", strings.Join(run.Errors, "; "))
+		}
+		result.Status = "partial"
+	}
+	return result, nil
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+
+	"templui/internal/ai"
+	"templui/internal/database"
+	"templui/internal/metrics"
+	"templui/internal/models"
+	"templui/ui/pages"
+)
+
+// budgetError is returned instead of calling a provider when a monthly
+// budget would be exceeded
+type budgetError struct {
+	scope  string  // "project" or "installation"
+	spent  float64 // Including requests in flight
+	budget float64
+}
+
+func (e *budgetError) Error() string {
+	return fmt.Sprintf("monthly AI budget of the %s is used up ($%.2f of $%.2f spent)", e.scope, e.spent, e.budget)
+}
+
+// usageAccount records the AI usage of a translation run and enforces the
+// project and global monthly budgets before each provider request. Reserved
+// estimates are released after the request, once its actual cost is recorded.
+type usageAccount struct {
+	db        *database.DB
+	projectID string
+	jobID     string
+	batchID   string
+	author    changeAuthor
+	budget    float64 // Project budget, 0 for none
+}
+
+// translate checks the budgets, translates with ai.Translate and records the
+// usage of every attempt, failed ones included
+func (a *usageAccount) translate(ctx context.Context, providers []models.MTProvider, req ai.Request) (*ai.Result, error) {
+	estimate, err := a.reserve(ctx, ai.EstimateCost(providers, req))
+	if err != nil {
+		return nil, err
+	}
+	defer a.release(estimate)
+
+	result, err := ai.Translate(ctx, providers, req)
+	if result != nil {
+		for _, attempt := range result.Attempts {
+			a.record(ctx, attempt.Translator, attempt.Usage)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// assess checks the budgets, reviews translations with ai.EstimateQuality and records the usage
+func (a *usageAccount) assess(ctx context.Context, providers []models.MTProvider, req ai.QualityRequest) (*ai.QualityResult, error) {
+	translator, err := ai.QualityTranslatorName(providers)
+	if err != nil {
+		return nil, err
+	}
+	estimate, err := a.reserve(ctx, ai.EstimateQualityUsage(req).Cost(translator))
+	if err != nil {
+		return nil, err
+	}
+	defer a.release(estimate)
+
+	result, err := ai.EstimateQuality(ctx, providers, req)
+	if result != nil && result.Usage != (ai.Usage{}) {
+		a.record(ctx, result.Translator, result.Usage)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// reservations hold the estimated cost of requests in flight per project, and
+// for all projects under "", so parallel batches cannot overspend a budget together
+var reservations = struct {
+	sync.Mutex
+	cost map[string]float64
+}{cost: make(map[string]float64)}
+
+// reserve reserves the estimated cost of a request, or refuses the request
+// when that would exceed the project or global budget
+func (a *usageAccount) reserve(ctx context.Context, estimate float64) (float64, error) {
+	global := ai.MonthlyBudget()
+	if a.budget <= 0 && global <= 0 {
+		return 0, nil
+	}
+	month := models.UsageMonth(time.Now())
+
+	reservations.Lock()
+	defer reservations.Unlock()
+	check := func(scope, projectID string, budget float64) error {
+		if budget <= 0 {
+			return nil
+		}
+		spent, err := a.db.AIUsageCost(projectID, month)
+		if err != nil {
+			return fmt.Errorf("failed to check AI budget: %w", err)
+		}
+		spent += reservations.cost[projectID]
+		if spent+estimate > budget {
+			metrics.RecordAIBudgetRejection(ctx, a.projectID, scope)
+			return &budgetError{scope: scope, spent: spent, budget: budget}
+		}
+		return nil
+	}
+	if err := check("project", a.projectID, a.budget); err != nil {
+		return 0, err
+	}
+	if err := check("installation", "", global); err != nil {
+		return 0, err
+	}
+	reservations.cost[a.projectID] += estimate
+	reservations.cost[""] += estimate
+	return estimate, nil
+}
+
+// release frees the reservation of a finished request
+func (a *usageAccount) release(estimate float64) {
+	if estimate == 0 {
+		return
+	}
+	reservations.Lock()
+	defer reservations.Unlock()
+	for _, id := range []string{a.projectID, ""} {
+		if reservations.cost[id] -= estimate; reservations.cost[id] <= 1e-9 {
+			delete(reservations.cost, id)
+		}
+	}
+}
+
+// record stores the usage of a completed request and exports it as metrics
+func (a *usageAccount) record(ctx context.Context, translator string, u ai.Usage) {
+	now := time.Now().UTC()
+	usage := &models.AIUsage{
+		ID:               generateID(),
+		ProjectID:        a.projectID,
+		JobID:            a.jobID,
+		BatchID:          a.batchID,
+		AuthorType:       a.author.Type,
+		AuthorID:         a.author.ID,
+		Translator:       translator,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		Characters:       u.Characters,
+		Cost:             u.Cost(translator),
+		Month:            models.UsageMonth(now),
+		CreatedAt:        now,
+	}
+	if err := a.db.RecordAIUsage(usage); err != nil {
+		log.Errorf("Failed to record AI usage of project %s: %v", a.projectID, err)
+	}
+	metrics.RecordAIUsage(ctx, usage.ProjectID, usage.Translator, usage.PromptTokens, usage.CompletionTokens, usage.Characters, usage.Cost)
+}
+
+type UsageHandler struct {
+	db *database.DB
+}
+
+func NewUsageHandler(db *database.DB) *UsageHandler {
+	return &UsageHandler{db: db}
+}
+
+// monthPattern matches a billing month such as "2026-10"
+var monthPattern = regexp.MustCompile(`^\d{4}-\d{2}$`)
+
+// usageMonth returns the ?month= of a request, defaulting to the current month
+func usageMonth(c echo.Context) (string, bool) {
+	month := c.QueryParam("month")
+	if month == "" {
+		return models.UsageMonth(time.Now()), true
+	}
+	return month, monthPattern.MatchString(month)
+}
+
+// UsagePage handles GET /usage
+func (h *UsageHandler) UsagePage(c echo.Context) error {
+	month, ok := usageMonth(c)
+	if !ok {
+		month = models.UsageMonth(time.Now())
+	}
+	return render(c, pages.Usage(month))
+}
+
+// Overview handles GET /api/usage?month=2026-10
+// Returns the AI usage and budgets of the projects the caller can access.
+func (h *UsageHandler) Overview(c echo.Context) error {
+	month, ok := usageMonth(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "month must be YYYY-MM"})
+	}
+	projectIDs, err := accessibleProjects(c, h.db)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get projects"})
+	}
+	totals, err := h.db.AIUsageTotals(projectIDs, month, database.UsageByProject)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get usage"})
+	}
+	byProject := make(map[string]models.UsageTotal, len(totals))
+	for _, t := range totals {
+		byProject[t.Group] = t
+	}
+
+	overview := models.UsageOverview{Month: month, Projects: []models.ProjectUsage{}, GlobalBudget: ai.MonthlyBudget()}
+	for _, id := range projectIDs {
+		project, err := h.db.GetProject(id)
+		if err != nil {
+			continue
+		}
+		total := byProject[id]
+		total.Group = id
+		overview.Projects = append(overview.Projects, models.ProjectUsage{
+			ProjectID:   id,
+			ProjectName: project.Name,
+			Total:       total,
+			Budget:      project.MTSettings.MonthlyBudget,
+		})
+		overview.Total.Add(total)
+	}
+	slices.SortStableFunc(overview.Projects, func(a, b models.ProjectUsage) int {
+		return cmp.Compare(b.Total.Cost, a.Total.Cost)
+	})
+	if overview.GlobalBudget > 0 {
+		if overview.GlobalCost, err = h.db.AIUsageCost("", month); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get usage"})
+		}
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return render(c, pages.UsageOverview(overview))
+	}
+	return c.JSON(http.StatusOK, overview)
+}
+
+// ProjectUsage handles GET /api/project/:id/usage?month=2026-10
+// Breaks down a project's AI usage by translator, author and day, with its latest requests.
+func (h *UsageHandler) ProjectUsage(c echo.Context) error {
+	projectID := c.Param("id")
+	month, ok := usageMonth(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "month must be YYYY-MM"})
+	}
+	projectIDs, err := accessibleProjects(c, h.db)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get projects"})
+	}
+	if !slices.Contains(projectIDs, projectID) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+
+	report := models.UsageReport{ProjectID: projectID, Month: month, Budget: project.MTSettings.MonthlyBudget}
+	ids := []string{projectID}
+	for _, g := range []struct {
+		groupBy string
+		totals  *[]models.UsageTotal
+	}{
+		{database.UsageByTranslator, &report.ByTranslator},
+		{database.UsageByAuthor, &report.ByAuthor},
+		{database.UsageByDay, &report.ByDay},
+	} {
+		if *g.totals, err = h.db.AIUsageTotals(ids, month, g.groupBy); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get usage"})
+		}
+	}
+	for _, t := range report.ByTranslator {
+		report.Total.Add(t)
+	}
+	if report.Recent, err = h.db.GetRecentAIUsage(projectID, 50); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get usage"})
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return render(c, pages.ProjectUsageReport(report))
+	}
+	return c.JSON(http.StatusOK, report)
+}
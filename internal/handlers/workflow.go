@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"templui/internal/database"
+	"templui/internal/models"
+	"templui/internal/session"
+)
+
+// sharePermissions are the permissions an owner may grant to a share link
+var sharePermissions = map[string]bool{
+	"read":    true,
+	"write":   true,
+	"review":  true,
+	"suggest": true,
+}
+
+type WorkflowHandler struct {
+	db *database.DB
+}
+
+func NewWorkflowHandler(db *database.DB) *WorkflowHandler {
+	return &WorkflowHandler{db: db}
+}
+
+// SetStatus handles POST /api/project/:id/status?key=&status=
+func (h *WorkflowHandler) SetStatus(c echo.Context) error {
+	projectID := c.Param("id")
+	key := c.FormValue("key")
+	status := c.FormValue("status")
+
+	data, err := loadProjectData(h.db, projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if _, ok := data.BaseFlat[key]; !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Key not found"})
+	}
+	if data.TargetFlat[key] == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Untranslated keys have no workflow status"})
+	}
+
+	role := requestRole(c, h.db, data.Project)
+	current := data.status(key)
+	if !models.CanTransition(role, current, status) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": fmt.Sprintf("A %s cannot change %s to %s", role, current, status)})
+	}
+
+	author := requestAuthor(c, h.db, projectID)
+	if err := h.db.SetStatus(data.TargetFile.ID, key, status, author.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update status"})
+	}
+	data.Statuses[key] = models.TranslationStatus{FileID: data.TargetFile.ID, Key: key, Status: status, UpdatedBy: author.ID, UpdatedAt: time.Now()}
+	if status == models.StatusApproved {
+		rememberApproved(h.db, data, key)
+	}
+
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return renderField(c, h.db, data, key, "")
+	}
+	return c.JSON(http.StatusOK, map[string]string{"key": key, "status": status})
+}
+
+// Statuses handles GET /api/project/:id/statuses
+// Returns the status of every key together with counts and percentages per status.
+func (h *WorkflowHandler) Statuses(c echo.Context) error {
+	data, err := loadProjectData(h.db, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	keys := make(map[string]string, len(data.BaseFlat))
+	for key := range data.BaseFlat {
+		keys[key] = data.status(key)
+	}
+
+	counts := data.statusCounts()
+	percentages := make(map[string]float64, len(counts))
+	for status, n := range counts {
+		percentages[status] = 0
+		if len(data.BaseFlat) > 0 {
+			percentages[status] = float64(n) / float64(len(data.BaseFlat)) * 100.0
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"keys":        keys,
+		"counts":      counts,
+		"percentages": percentages,
+	})
+}
+
+// CreateKeyRequest represents the request body for creating a share link
+type CreateKeyRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// CreateKey handles POST /api/project/:id/keys
+// Creates a share link with the given permissions, e.g. ["read", "write", "review"] for reviewers.
+func (h *WorkflowHandler) CreateKey(c echo.Context) error {
+	projectID := c.Param("id")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Project not found"})
+	}
+	if project.SessionToken != session.GetSessionToken(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Unauthorized"})
+	}
+
+	var req CreateKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+	switch c.QueryParam("role") {
+	case models.RoleReviewer:
+		req.Permissions = []string{"read", "write", "review"}
+	case models.RoleSuggester:
+		req.Permissions = []string{"read", "suggest"}
+	}
+	if len(req.Permissions) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "At least one permission is required"})
+	}
+	for _, p := range req.Permissions {
+		if !sharePermissions[p] {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown permission: " + p})
+		}
+	}
+
+	apiKey, keyHash := generateAPIKey()
+	record := &models.APIKey{
+		ID:          generateID(),
+		ProjectID:   projectID,
+		KeyHash:     keyHash,
+		Permissions: req.Permissions,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.db.CreateAPIKey(record); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create API key"})
+	}
+
+	url := fmt.Sprintf("/project/%s/edit?key=%s", projectID, apiKey)
+	if c.Request().Header.Get("HX-Request") == "true" {
+		return c.String(http.StatusCreated, c.Scheme()+"://"+c.Request().Host+url)
+	}
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"api_key":     apiKey,
+		"permissions": record.Permissions,
+		"url":         url,
+	})
+}
@@ -0,0 +1,238 @@
+// Package jobs runs long-running operations such as Auto Translate in
+// background workers. Jobs are persisted in the database, so their status
+// survives the request that started them and interrupted jobs are resumed
+// after a restart.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/labstack/gommon/log"
+
+	"templui/internal/database"
+	"templui/internal/events"
+	"templui/internal/models"
+)
+
+// MaxAttempts is how often a job is started before it is given up after restarts
+const MaxAttempts = 3
+
+// pollInterval is how often idle workers look for queued jobs and running
+// jobs check whether they were cancelled
+const pollInterval = time.Second
+
+// Progress reports how many units of a job are done out of total
+type Progress func(done, total int)
+
+// Handler executes a job and returns a result that is stored as JSON
+type Handler func(ctx context.Context, job *models.Job, progress Progress) (interface{}, error)
+
+// Queue hands persisted jobs to a fixed number of workers
+type Queue struct {
+	db        *database.DB
+	workers   int
+	handlers  map[string]Handler
+	transient map[string][]string // Job type -> parameters dropped once a job has finished
+	wake      chan struct{}
+}
+
+// NewQueue creates a queue with the given number of workers
+func NewQueue(db *database.DB, workers int) *Queue {
+	return &Queue{
+		db:        db,
+		workers:   max(workers, 1),
+		handlers:  make(map[string]Handler),
+		transient: make(map[string][]string),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler for a job type. Handlers must be registered before Start.
+func (q *Queue) Register(jobType string, h Handler) {
+	q.handlers[jobType] = h
+}
+
+// Transient marks parameters of a job type that are only needed while a job
+// runs, such as uploaded content. They are removed once the job has finished.
+func (q *Queue) Transient(jobType string, fields ...string) {
+	q.transient[jobType] = append(q.transient[jobType], fields...)
+}
+
+// Enqueue persists a new job and wakes a worker
+func (q *Queue) Enqueue(job *models.Job) error {
+	if _, ok := q.handlers[job.Type]; !ok {
+		return fmt.Errorf("unknown job type: %s", job.Type)
+	}
+	job.Status = models.JobQueued
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+	if err := q.db.CreateJob(job); err != nil {
+		return err
+	}
+	q.notify()
+	return nil
+}
+
+// EnqueueUnique is Enqueue for jobs that must not run twice at once for a
+// project. When the project already has a queued or running job of the type,
+// that job is returned instead and no new one is created.
+func (q *Queue) EnqueueUnique(job *models.Job) (*models.Job, error) {
+	if _, ok := q.handlers[job.Type]; !ok {
+		return nil, fmt.Errorf("unknown job type: %s", job.Type)
+	}
+	job.Status = models.JobQueued
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+	activeID, err := q.db.CreateJobUnlessActive(job)
+	if err != nil {
+		return nil, err
+	}
+	if activeID != "" {
+		return q.db.GetJob(activeID)
+	}
+	q.notify()
+	return job, nil
+}
+
+// notify wakes an idle worker
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start requeues jobs interrupted by a restart and starts the workers
+func (q *Queue) Start(ctx context.Context) error {
+	n, err := q.db.RequeueInterruptedJobs(MaxAttempts)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Infof("Requeued %d interrupted jobs", n)
+	}
+	q.prune()
+	for i := 0; i < q.workers; i++ {
+		go q.work(ctx)
+	}
+	return nil
+}
+
+// work runs queued jobs until ctx is done
+func (q *Queue) work(ctx context.Context) {
+	for {
+		job, err := q.db.ClaimJob()
+		if err != nil {
+			log.Errorf("Failed to claim job: %v", err)
+		}
+		if job != nil {
+			q.publish(job.ID)
+			q.run(ctx, job)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// errCancelled marks jobs stopped by a cancel request
+var errCancelled = errors.New("job cancelled")
+
+// run executes a claimed job and stores its outcome
+func (q *Queue) run(ctx context.Context, job *models.Job) {
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// Watch for cancel requests while the job runs
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+				if requested, err := q.db.IsJobCancelRequested(job.ID); err == nil && requested {
+					cancel(errCancelled)
+					return
+				}
+			}
+		}
+	}()
+
+	progress := func(done, total int) {
+		if err := q.db.UpdateJobProgress(job.ID, done, total); err != nil {
+			log.Errorf("Failed to update progress of job %s: %v", job.ID, err)
+			return
+		}
+		q.publish(job.ID)
+	}
+
+	result, err := q.execute(jobCtx, job, progress)
+	status, message := models.JobSucceeded, ""
+	switch {
+	case errors.Is(context.Cause(jobCtx), errCancelled):
+		status, message = models.JobCancelled, "Cancelled"
+	case err != nil:
+		status, message = models.JobFailed, err.Error()
+	}
+	if ctx.Err() != nil && status != models.JobCancelled {
+		// The server is shutting down, leave the job running so it is requeued on boot
+		return
+	}
+
+	var data []byte
+	if result != nil {
+		if data, err = json.Marshal(result); err != nil {
+			status, message = models.JobFailed, "Failed to encode result: "+err.Error()
+		}
+	}
+	if err := q.db.FinishJob(job.ID, status, data, message); err != nil {
+		log.Errorf("Failed to finish job %s: %v", job.ID, err)
+	}
+	q.prune()
+	q.publish(job.ID)
+}
+
+// prune removes the transient parameters of finished jobs. Jobs cancelled
+// before they started are pruned along with the next finished one.
+func (q *Queue) prune() {
+	for jobType, fields := range q.transient {
+		if err := q.db.RemoveJobParams(jobType, fields); err != nil {
+			log.Errorf("Failed to remove parameters of finished %s jobs: %v", jobType, err)
+		}
+	}
+}
+
+// publish sends the current state of a project job to the project's live events
+func (q *Queue) publish(id string) {
+	job, err := q.db.GetJob(id)
+	if err != nil || job.ProjectID == "" {
+		return
+	}
+	events.Publish(job.ProjectID, events.Event{Type: events.TypeJob, Data: events.NewJobEvent(job)})
+}
+
+// execute calls the job's handler, turning panics into errors
+func (q *Queue) execute(ctx context.Context, job *models.Job, progress Progress) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	h, ok := q.handlers[job.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown job type: %s", job.Type)
+	}
+	return h(ctx, job, progress)
+}
@@ -0,0 +1,241 @@
+package jsontools
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// RenameThreshold is the minimum value similarity for a removed and an added
+// key to be considered the same string under a new name
+const RenameThreshold = 0.8
+
+// Rename is a key that was moved to a new name in the base file
+type Rename struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Similarity float64 `json:"similarity"` // 1 when the source text is unchanged
+}
+
+// BaseUpdatePlan describes how replacing the base file affects its keys
+type BaseUpdatePlan struct {
+	Added   map[string]string `json:"added"`
+	Removed map[string]string `json:"removed"`
+	Changed map[string]Change `json:"changed"` // Source text changed, translations need review
+	Renamed []Rename          `json:"renamed"`
+}
+
+// PlanBaseUpdate compares the current and new base file. When renames is nil,
+// renames are detected by value similarity; otherwise the given old → new key
+// mapping is used and must only reference removed and added keys.
+func PlanBaseUpdate(old, new map[string]string, renames map[string]string) (BaseUpdatePlan, error) {
+	cs := DiffVersions(old, new)
+	plan := BaseUpdatePlan{
+		Added:   cs.Added,
+		Removed: cs.Removed,
+		Changed: cs.Changed,
+		Renamed: []Rename{},
+	}
+
+	if renames == nil {
+		plan.Renamed = DetectRenames(cs.Removed, cs.Added)
+	} else {
+		for from, to := range renames {
+			if _, ok := cs.Removed[from]; !ok {
+				return plan, fmt.Errorf("rename source %q is not a removed key", from)
+			}
+			if _, ok := cs.Added[to]; !ok {
+				return plan, fmt.Errorf("rename target %q is not an added key", to)
+			}
+			plan.Renamed = append(plan.Renamed, Rename{From: from, To: to, Similarity: Similarity(cs.Removed[from], cs.Added[to])})
+		}
+		sort.Slice(plan.Renamed, func(i, j int) bool { return plan.Renamed[i].From < plan.Renamed[j].From })
+	}
+
+	for _, r := range plan.Renamed {
+		delete(plan.Removed, r.From)
+		delete(plan.Added, r.To)
+	}
+	return plan, nil
+}
+
+// MigrateTarget applies the plan to a flattened target file. Translations of
+// renamed keys move to the new name, removed keys are dropped and added keys
+// are created empty. It returns the migrated values and the translated keys
+// whose source text changed and therefore need review.
+func (p BaseUpdatePlan) MigrateTarget(target map[string]string) (map[string]string, []string) {
+	migrated := make(map[string]string, len(target))
+	for key, value := range target {
+		migrated[key] = value
+	}
+
+	var review []string
+	for _, r := range p.Renamed {
+		value := migrated[r.From]
+		delete(migrated, r.From)
+		migrated[r.To] = value
+		if value != "" && r.Similarity < 1 {
+			review = append(review, r.To)
+		}
+	}
+	for key := range p.Removed {
+		delete(migrated, key)
+	}
+	for key := range p.Added {
+		if _, exists := migrated[key]; !exists {
+			migrated[key] = ""
+		}
+	}
+	for key := range p.Changed {
+		if migrated[key] != "" {
+			review = append(review, key)
+		}
+	}
+
+	sort.Strings(review)
+	return migrated, review
+}
+
+// DetectRenames pairs removed and added keys whose values are similar enough
+// to be the same string. The most similar pairs are matched first; ties are
+// broken by the similarity of the key names. Keys with the same value are
+// found through an index first, so only the rest are compared pairwise.
+func DetectRenames(removed, added map[string]string) []Rename {
+	renames := []Rename{}
+	usedFrom := make(map[string]bool)
+	usedTo := make(map[string]bool)
+	match := func(candidates []renameCandidate) {
+		sortCandidates(candidates)
+		for _, c := range candidates {
+			if usedFrom[c.From] || usedTo[c.To] {
+				continue
+			}
+			usedFrom[c.From] = true
+			usedTo[c.To] = true
+			renames = append(renames, c.Rename)
+		}
+	}
+
+	// Unchanged values: every pair has similarity 1 and sorts before the rest
+	byValue := make(map[string][]string, len(added))
+	for to, value := range added {
+		norm := normalizeValue(value)
+		byValue[norm] = append(byValue[norm], to)
+	}
+	var exact []renameCandidate
+	for from, value := range removed {
+		for _, to := range byValue[normalizeValue(value)] {
+			exact = append(exact, newRenameCandidate(from, to, 1))
+		}
+	}
+	match(exact)
+
+	// Changed values: only values of a similar length can reach the threshold
+	type entry struct {
+		key    string
+		value  string
+		length int
+	}
+	var rest []entry
+	for to, value := range added {
+		if !usedTo[to] {
+			rest = append(rest, entry{to, value, len([]rune(normalizeValue(value)))})
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].length < rest[j].length })
+	var fuzzy []renameCandidate
+	for from, oldVal := range removed {
+		if usedFrom[from] {
+			continue
+		}
+		length := len([]rune(normalizeValue(oldVal)))
+		minLength := int(math.Ceil(float64(length)*RenameThreshold - 1e-9))
+		maxLength := int(float64(length)/RenameThreshold + 1e-9)
+		for i := sort.Search(len(rest), func(i int) bool { return rest[i].length >= minLength }); i < len(rest) && rest[i].length <= maxLength; i++ {
+			sim := Similarity(oldVal, rest[i].value)
+			if sim >= RenameThreshold {
+				fuzzy = append(fuzzy, newRenameCandidate(from, rest[i].key, sim))
+			}
+		}
+	}
+	match(fuzzy)
+
+	sort.Slice(renames, func(i, j int) bool { return renames[i].From < renames[j].From })
+	return renames
+}
+
+// renameCandidate is a possible rename with the similarity of its key names
+type renameCandidate struct {
+	Rename
+	keySim float64
+}
+
+func newRenameCandidate(from, to string, sim float64) renameCandidate {
+	return renameCandidate{
+		Rename: Rename{From: from, To: to, Similarity: sim},
+		keySim: Similarity(lastSegment(from), lastSegment(to)),
+	}
+}
+
+// sortCandidates orders candidates by value similarity, then key similarity
+func sortCandidates(candidates []renameCandidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+		if a.keySim != b.keySim {
+			return a.keySim > b.keySim
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+}
+
+// normalizeValue is the form of a value that Similarity compares
+func normalizeValue(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// Similarity returns a score between 0 and 1 based on the edit distance of
+// the case-folded, trimmed strings
+func Similarity(a, b string) float64 {
+	ra := []rune(normalizeValue(a))
+	rb := []rune(normalizeValue(b))
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// lastSegment returns the part of a flattened key after the final dot
+func lastSegment(key string) string {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
@@ -0,0 +1,183 @@
+package jsontools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectRenames(t *testing.T) {
+	tests := []struct {
+		name    string
+		removed map[string]string
+		added   map[string]string
+		want    []Rename
+	}{
+		{
+			name:    "unchanged value",
+			removed: map[string]string{"home.title": "Home"},
+			added:   map[string]string{"nav.home": "Home"},
+			want:    []Rename{{From: "home.title", To: "nav.home", Similarity: 1}},
+		},
+		{
+			name:    "case and whitespace are ignored",
+			removed: map[string]string{"a": "Save changes"},
+			added:   map[string]string{"b": " save Changes "},
+			want:    []Rename{{From: "a", To: "b", Similarity: 1}},
+		},
+		{
+			name:    "small edit",
+			removed: map[string]string{"old": "Delete project"},
+			added:   map[string]string{"new": "Delete projects"},
+			want:    []Rename{{From: "old", To: "new", Similarity: Similarity("Delete project", "Delete projects")}},
+		},
+		{
+			name:    "below threshold",
+			removed: map[string]string{"old": "Delete project"},
+			added:   map[string]string{"new": "Archive everything"},
+			want:    []Rename{},
+		},
+		{
+			name:    "tie broken by key name",
+			removed: map[string]string{"form.cancel": "Cancel", "dialog.close": "Cancel"},
+			added:   map[string]string{"actions.cancel": "Cancel", "actions.close": "Cancel"},
+			want: []Rename{
+				{From: "dialog.close", To: "actions.close", Similarity: 1},
+				{From: "form.cancel", To: "actions.cancel", Similarity: 1},
+			},
+		},
+		{
+			name:    "exact match wins over fuzzy",
+			removed: map[string]string{"a": "Open file"},
+			added:   map[string]string{"b": "Open files", "c": "Open file"},
+			want:    []Rename{{From: "a", To: "c", Similarity: 1}},
+		},
+		{
+			name:    "each key used once",
+			removed: map[string]string{"a": "Next", "b": "Next"},
+			added:   map[string]string{"c": "Next"},
+			want:    []Rename{{From: "a", To: "c", Similarity: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectRenames(tt.removed, tt.added)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectRenames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanBaseUpdate(t *testing.T) {
+	old := map[string]string{
+		"home.title": "Home",
+		"home.sub":   "Welcome",
+		"gone":       "Legacy",
+		"save":       "Save",
+	}
+	new := map[string]string{
+		"nav.home":   "Home",
+		"home.sub":   "Welcome back",
+		"save":       "Save",
+		"brand.name": "Acme",
+	}
+
+	t.Run("detected renames", func(t *testing.T) {
+		plan, err := PlanBaseUpdate(old, new, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []Rename{{From: "home.title", To: "nav.home", Similarity: 1}}; !reflect.DeepEqual(plan.Renamed, want) {
+			t.Errorf("Renamed = %v, want %v", plan.Renamed, want)
+		}
+		if want := map[string]string{"gone": "Legacy"}; !reflect.DeepEqual(plan.Removed, want) {
+			t.Errorf("Removed = %v, want %v", plan.Removed, want)
+		}
+		if want := map[string]string{"brand.name": "Acme"}; !reflect.DeepEqual(plan.Added, want) {
+			t.Errorf("Added = %v, want %v", plan.Added, want)
+		}
+		if _, ok := plan.Changed["home.sub"]; !ok || len(plan.Changed) != 1 {
+			t.Errorf("Changed = %v, want only home.sub", plan.Changed)
+		}
+	})
+
+	t.Run("explicit renames", func(t *testing.T) {
+		plan, err := PlanBaseUpdate(old, new, map[string]string{"gone": "brand.name"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []Rename{{From: "gone", To: "brand.name", Similarity: Similarity("Legacy", "Acme")}}; !reflect.DeepEqual(plan.Renamed, want) {
+			t.Errorf("Renamed = %v, want %v", plan.Renamed, want)
+		}
+		if _, ok := plan.Removed["home.title"]; !ok {
+			t.Errorf("Removed = %v, want home.title kept as removed", plan.Removed)
+		}
+		if _, ok := plan.Added["nav.home"]; !ok {
+			t.Errorf("Added = %v, want nav.home kept as added", plan.Added)
+		}
+	})
+
+	t.Run("empty explicit renames disable detection", func(t *testing.T) {
+		plan, err := PlanBaseUpdate(old, new, map[string]string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plan.Renamed) != 0 {
+			t.Errorf("Renamed = %v, want none", plan.Renamed)
+		}
+	})
+
+	for name, renames := range map[string]map[string]string{
+		"source not removed": {"save": "brand.name"},
+		"target not added":   {"gone": "save"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := PlanBaseUpdate(old, new, renames); err == nil {
+				t.Error("PlanBaseUpdate() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestMigrateTarget(t *testing.T) {
+	plan := BaseUpdatePlan{
+		Added:   map[string]string{"brand.name": "Acme"},
+		Removed: map[string]string{"gone": "Legacy"},
+		Changed: map[string]Change{"home.sub": {Old: "Welcome", New: "Welcome back"}, "empty": {Old: "A", New: "B"}},
+		Renamed: []Rename{
+			{From: "home.title", To: "nav.home", Similarity: 1},
+			{From: "delete", To: "actions.delete", Similarity: 0.9},
+			{From: "blank", To: "actions.blank", Similarity: 0.9},
+		},
+	}
+	target := map[string]string{
+		"home.title": "Start",
+		"home.sub":   "Willkommen",
+		"delete":     "Löschen",
+		"blank":      "",
+		"empty":      "",
+		"gone":       "Alt",
+		"save":       "Speichern",
+	}
+
+	migrated, review := plan.MigrateTarget(target)
+
+	wantMigrated := map[string]string{
+		"nav.home":       "Start",
+		"home.sub":       "Willkommen",
+		"actions.delete": "Löschen",
+		"actions.blank":  "",
+		"empty":          "",
+		"save":           "Speichern",
+		"brand.name":     "",
+	}
+	if !reflect.DeepEqual(migrated, wantMigrated) {
+		t.Errorf("migrated = %v, want %v", migrated, wantMigrated)
+	}
+	if want := []string{"actions.delete", "home.sub"}; !reflect.DeepEqual(review, want) {
+		t.Errorf("review = %v, want %v", review, want)
+	}
+	if target["home.title"] != "Start" {
+		t.Error("MigrateTarget modified its input")
+	}
+}
@@ -1,10 +1,16 @@
 package jsontools
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
 // Difference represents the differences between two JSON structures
 type Difference struct {
 	MissingKeys     []string          // Keys present in base but missing in target
 	ExtraKeys       []string          // Keys present in target but not in base
 	DifferentValues map[string]Values // Keys with different values
+	OutdatedKeys    []string          // Translated keys whose base value changed since translation
 }
 
 // Values holds the base and target values for comparison
@@ -13,12 +19,36 @@ type Values struct {
 	Target string
 }
 
+// SourceHash fingerprints the base value a translation was made from
+func SourceHash(baseValue string) string {
+	hash := sha256.Sum256([]byte(baseValue))
+	return hex.EncodeToString(hash[:8])
+}
+
+// SourceHashes fingerprints every value of a flattened base file
+func SourceHashes(base map[string]string) map[string]string {
+	hashes := make(map[string]string, len(base))
+	for key, value := range base {
+		hashes[key] = SourceHash(value)
+	}
+	return hashes
+}
+
 // CompareJSON compares two flattened JSON maps and returns differences
 func CompareJSON(base, target map[string]string) Difference {
+	return CompareJSONWithSources(base, target, nil)
+}
+
+// CompareJSONWithSources compares two flattened JSON maps like CompareJSON and
+// additionally reports translated keys as outdated when the source hash they
+// were recorded with no longer matches the current base value. Keys without a
+// recorded hash are assumed to be current.
+func CompareJSONWithSources(base, target, sources map[string]string) Difference {
 	diff := Difference{
 		MissingKeys:     []string{},
 		ExtraKeys:       []string{},
 		DifferentValues: make(map[string]Values),
+		OutdatedKeys:    []string{},
 	}
 
 	// Find missing keys (in base but not in target, or empty in target)
@@ -29,6 +59,14 @@ func CompareJSON(base, target map[string]string) Difference {
 		}
 	}
 
+	// Find outdated keys (translated from a different base value)
+	for key, hash := range sources {
+		baseValue, exists := base[key]
+		if exists && target[key] != "" && hash != SourceHash(baseValue) {
+			diff.OutdatedKeys = append(diff.OutdatedKeys, key)
+		}
+	}
+
 	// Find extra keys (in target but not in base)
 	for key := range target {
 		if _, exists := base[key]; !exists {
@@ -56,6 +94,11 @@ func (d Difference) HasMissingTranslations() bool {
 	return len(d.MissingKeys) > 0
 }
 
+// HasOutdatedTranslations checks if there are any outdated keys
+func (d Difference) HasOutdatedTranslations() bool {
+	return len(d.OutdatedKeys) > 0
+}
+
 // TotalDifferences returns the total number of differences
 func (d Difference) TotalDifferences() int {
 	return len(d.MissingKeys) + len(d.ExtraKeys) + len(d.DifferentValues)
@@ -74,3 +117,57 @@ func CompletionPercentage(base, target map[string]string) float64 {
 	completed := total - missing
 	return (float64(completed) / float64(total)) * 100.0
 }
+
+// CompletionPercentageExcludingOutdated calculates the completion percentage,
+// counting outdated translations as incomplete
+func CompletionPercentageExcludingOutdated(base, target, sources map[string]string) float64 {
+	if len(base) == 0 {
+		return 100.0
+	}
+
+	diff := CompareJSONWithSources(base, target, sources)
+	completed := len(base) - len(diff.MissingKeys) - len(diff.OutdatedKeys)
+	return (float64(completed) / float64(len(base))) * 100.0
+}
+
+// ChangeSet describes how a flattened file changed between two versions
+type ChangeSet struct {
+	Added   map[string]string `json:"added"`   // Keys only in the new version
+	Removed map[string]string `json:"removed"` // Keys only in the old version
+	Changed map[string]Change `json:"changed"` // Keys whose value changed
+}
+
+// Change holds the old and new value of a key
+type Change struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// DiffVersions compares two versions of the same flattened file
+func DiffVersions(old, new map[string]string) ChangeSet {
+	cs := ChangeSet{
+		Added:   make(map[string]string),
+		Removed: make(map[string]string),
+		Changed: make(map[string]Change),
+	}
+	for key, newVal := range new {
+		oldVal, exists := old[key]
+		switch {
+		case !exists:
+			cs.Added[key] = newVal
+		case oldVal != newVal:
+			cs.Changed[key] = Change{Old: oldVal, New: newVal}
+		}
+	}
+	for key, oldVal := range old {
+		if _, exists := new[key]; !exists {
+			cs.Removed[key] = oldVal
+		}
+	}
+	return cs
+}
+
+// IsEmpty reports whether the versions are identical
+func (cs ChangeSet) IsEmpty() bool {
+	return len(cs.Added) == 0 && len(cs.Removed) == 0 && len(cs.Changed) == 0
+}
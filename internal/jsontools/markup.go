@@ -0,0 +1,187 @@
+package jsontools
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagRegex matches opening, closing and self-closing tags such as <b>, </b>,
+// <br/>, <a href="...">, and react-i18next Trans tags like <0>...</0>
+var tagRegex = regexp.MustCompile(`<(/?)([A-Za-z0-9][A-Za-z0-9:_-]*)((?:\s+[^<>]*?)?)\s*(/?)>`)
+
+var attrRegex = regexp.MustCompile(`([A-Za-z_:][A-Za-z0-9:._-]*)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+
+// translatableAttrs are attributes whose values are expected to be translated,
+// so only their presence is compared against the source
+var translatableAttrs = map[string]bool{
+	"title":       true,
+	"alt":         true,
+	"aria-label":  true,
+	"placeholder": true,
+}
+
+// Tag is a single markup tag found in a translation value
+type Tag struct {
+	Name        string
+	Attrs       map[string]string
+	Closing     bool
+	SelfClosing bool
+}
+
+// Signature returns a comparable representation of an opening tag and its
+// attributes. Void elements such as <br> and <br/> share a signature.
+func (t Tag) Signature() string {
+	keys := make([]string, 0, len(t.Attrs))
+	for k := range t.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("<" + t.Name)
+	for _, k := range keys {
+		if translatableAttrs[k] {
+			sb.WriteString(" " + k)
+			continue
+		}
+		sb.WriteString(fmt.Sprintf(" %s=%q", k, t.Attrs[k]))
+	}
+	if t.SelfClosing && !voidElements[t.Name] {
+		sb.WriteString("/")
+	}
+	sb.WriteString(">")
+	return sb.String()
+}
+
+// ExtractTags returns all tags found in the text in order of appearance
+func ExtractTags(text string) []Tag {
+	matches := tagRegex.FindAllStringSubmatch(text, -1)
+	tags := make([]Tag, 0, len(matches))
+	for _, m := range matches {
+		tag := Tag{
+			Name:        strings.ToLower(m[2]),
+			Attrs:       make(map[string]string),
+			Closing:     m[1] == "/",
+			SelfClosing: m[4] == "/",
+		}
+		for _, a := range attrRegex.FindAllStringSubmatch(m[3], -1) {
+			tag.Attrs[strings.ToLower(a[1])] = a[2] + a[3] + a[4]
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// HasMarkup reports whether the text contains any tags
+func HasMarkup(text string) bool {
+	return tagRegex.MatchString(text)
+}
+
+// voidElements are the HTML elements that have no closing tag, such as <br>
+var voidElements = map[string]bool{
+	"area":   true,
+	"base":   true,
+	"br":     true,
+	"col":    true,
+	"embed":  true,
+	"hr":     true,
+	"img":    true,
+	"input":  true,
+	"link":   true,
+	"meta":   true,
+	"source": true,
+	"track":  true,
+	"wbr":    true,
+}
+
+// checkNesting verifies that every closing tag matches the most recent open tag
+func checkNesting(tags []Tag) error {
+	var stack []string
+	for _, t := range tags {
+		switch {
+		case t.SelfClosing, voidElements[t.Name]:
+			continue
+		case !t.Closing:
+			stack = append(stack, t.Name)
+		default:
+			if len(stack) == 0 || stack[len(stack)-1] != t.Name {
+				return fmt.Errorf("tag </%s> is not properly nested", t.Name)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("tag <%s> is never closed", stack[len(stack)-1])
+	}
+	return nil
+}
+
+// ValidateMarkup checks that target contains the same tags and attributes as base,
+// correctly nested, and no tags that are not present in base
+func ValidateMarkup(base, target string) error {
+	baseTags := ExtractTags(base)
+	targetTags := ExtractTags(target)
+	if len(baseTags) == 0 && len(targetTags) == 0 {
+		return nil
+	}
+
+	if err := checkNesting(targetTags); err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, t := range baseTags {
+		if !t.Closing {
+			counts[t.Signature()]++
+		}
+	}
+	for _, t := range targetTags {
+		if t.Closing {
+			continue
+		}
+		sig := t.Signature()
+		if counts[sig] == 0 {
+			return fmt.Errorf("unexpected tag %s not present in source", sig)
+		}
+		counts[sig]--
+	}
+
+	missing := make([]string, 0)
+	for sig, n := range counts {
+		if n > 0 {
+			missing = append(missing, sig)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("missing required tag: %s", missing[0])
+	}
+
+	return nil
+}
+
+// ValidateValue runs all structural checks (placeholders and markup) that a
+// translated value must pass before it is saved
+func ValidateValue(base, target string) error {
+	if err := ValidatePlaceholders(base, target); err != nil {
+		return err
+	}
+	return ValidateMarkup(base, target)
+}
+
+// ValidateFlat validates every non-empty target value against its base value
+// and returns the failures keyed by translation key
+func ValidateFlat(base, target map[string]string) map[string]string {
+	failures := make(map[string]string)
+	for key, value := range target {
+		if value == "" {
+			continue
+		}
+		if err := ValidateValue(base[key], value); err != nil {
+			failures[key] = err.Error()
+		}
+	}
+	return failures
+}
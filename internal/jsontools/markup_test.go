@@ -0,0 +1,29 @@
+package jsontools
+
+import "testing"
+
+func TestValidateMarkup(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		target  string
+		wantErr bool
+	}{
+		{name: "void element written self-closing", base: "Line<br>two", target: "Rivi<br/>kaksi"},
+		{name: "self-closing void element written open", base: "Line<br />two", target: "Rivi<br>kaksi"},
+		{name: "void element with attributes", base: `<img src="a.png" alt="Logo">`, target: `<img alt="Logo" src="a.png"/>`},
+		{name: "self-closing component keeps its flag", base: "Press <0/> to continue", target: "Paina <0></0> jatkaaksesi", wantErr: true},
+		{name: "translated attribute", base: `<a href="/x" title="Open">Open</a>`, target: `<a href="/x" title="Avaa">Avaa</a>`},
+		{name: "changed attribute", base: `<a href="/x">Open</a>`, target: `<a href="/y">Avaa</a>`, wantErr: true},
+		{name: "missing tag", base: "<b>Bold</b> text", target: "Lihava teksti", wantErr: true},
+		{name: "improper nesting", base: "<b><i>x</i></b>", target: "<b><i>x</b></i>", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMarkup(tt.base, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMarkup(%q, %q) error = %v, wantErr %v", tt.base, tt.target, err, tt.wantErr)
+			}
+		})
+	}
+}
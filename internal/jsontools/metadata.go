@@ -0,0 +1,146 @@
+package jsontools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"templui/internal/models"
+)
+
+// ExtractARBMetadata removes ARB metadata entries ("@key" and "@@locale") from the
+// data and returns the translatable content together with the parsed metadata
+func ExtractARBMetadata(data map[string]interface{}) (map[string]interface{}, map[string]models.KeyMetadata) {
+	content := make(map[string]interface{}, len(data))
+	meta := make(map[string]models.KeyMetadata)
+
+	for key, value := range data {
+		if !strings.HasPrefix(key, "@") {
+			content[key] = value
+			continue
+		}
+		if strings.HasPrefix(key, "@@") {
+			continue
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		m := metadataFromFields(strings.TrimPrefix(key, "@"), fields)
+		if !m.IsEmpty() {
+			meta[m.Key] = m
+		}
+	}
+
+	return content, meta
+}
+
+// ParseMetadataFile parses a sidecar metadata JSON file of the form
+// {"key.path": {"description": "...", "max_length": 20, ...}}.
+// ARB-style "@key" entries are accepted as well.
+func ParseMetadataFile(content []byte) (map[string]models.KeyMetadata, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	meta := make(map[string]models.KeyMetadata)
+	for key, value := range data {
+		if strings.HasPrefix(key, "@@") {
+			continue
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			// Plain ARB values are ignored so a full ARB file can be used as sidecar
+			continue
+		}
+		m := metadataFromFields(strings.TrimPrefix(key, "@"), fields)
+		if !m.IsEmpty() {
+			meta[m.Key] = m
+		}
+	}
+	return meta, nil
+}
+
+// metadataFromFields reads metadata fields, accepting snake_case, camelCase and
+// ARB "x-" extension spellings
+func metadataFromFields(key string, fields map[string]interface{}) models.KeyMetadata {
+	m := models.KeyMetadata{Key: key}
+	for name, value := range fields {
+		switch strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(strings.TrimPrefix(name, "x-"))) {
+		case "description":
+			m.Description = stringField(value)
+		case "context":
+			m.Context = stringField(value)
+		case "maxlength":
+			m.MaxLength = intField(value)
+		case "tags":
+			m.Tags = tagsField(value)
+		case "screenshot":
+			m.Screenshot = stringField(value)
+		case "donottranslate", "translatable":
+			b := boolField(value)
+			if name == "translatable" || name == "x-translatable" {
+				b = !b
+			}
+			m.DoNotTranslate = b
+		}
+	}
+	return m
+}
+
+func stringField(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func intField(v interface{}) int {
+	switch t := v.(type) {
+	case float64:
+		return int(t)
+	case string:
+		n, _ := strconv.Atoi(t)
+		return n
+	}
+	return 0
+}
+
+func boolField(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case string:
+		b, _ := strconv.ParseBool(t)
+		return b
+	}
+	return false
+}
+
+func tagsField(v interface{}) []string {
+	var tags []string
+	switch t := v.(type) {
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				tags = append(tags, strings.TrimSpace(s))
+			}
+		}
+	case string:
+		tags = ParseTags(t)
+	}
+	return tags
+}
+
+// ParseTags splits a comma-separated tag list, dropping empty entries
+func ParseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
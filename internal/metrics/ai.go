@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// aiInstruments count machine translation usage. They are created on first
+// use, through the global meter provider set up by StartMetrics.
+var aiInstruments struct {
+	once       sync.Once
+	requests   metric.Int64Counter
+	tokens     metric.Int64Counter
+	characters metric.Int64Counter
+	cost       metric.Float64Counter
+	rejections metric.Int64Counter
+}
+
+func initAIInstruments() {
+	meter := otel.Meter("translations/ai")
+	aiInstruments.requests, _ = meter.Int64Counter("ai.requests",
+		metric.WithDescription("Machine translation requests"))
+	aiInstruments.tokens, _ = meter.Int64Counter("ai.tokens",
+		metric.WithDescription("Tokens used by LLM translation requests"))
+	aiInstruments.characters, _ = meter.Int64Counter("ai.characters",
+		metric.WithDescription("Characters billed by non-LLM translation providers"))
+	aiInstruments.cost, _ = meter.Float64Counter("ai.cost",
+		metric.WithDescription("Estimated cost of machine translation requests"), metric.WithUnit("USD"))
+	aiInstruments.rejections, _ = meter.Int64Counter("ai.budget.rejections",
+		metric.WithDescription("Machine translation requests refused because a monthly budget was exhausted"))
+}
+
+// RecordAIUsage counts one machine translation request of a project
+func RecordAIUsage(ctx context.Context, projectID, translator string, promptTokens, completionTokens, characters int, cost float64) {
+	aiInstruments.once.Do(initAIInstruments)
+	attrs := metric.WithAttributes(attribute.String("project", projectID), attribute.String("translator", translator))
+	aiInstruments.requests.Add(ctx, 1, attrs)
+	aiInstruments.tokens.Add(ctx, int64(promptTokens), metric.WithAttributes(
+		attribute.String("project", projectID), attribute.String("translator", translator), attribute.String("type", "prompt")))
+	aiInstruments.tokens.Add(ctx, int64(completionTokens), metric.WithAttributes(
+		attribute.String("project", projectID), attribute.String("translator", translator), attribute.String("type", "completion")))
+	aiInstruments.characters.Add(ctx, int64(characters), attrs)
+	aiInstruments.cost.Add(ctx, cost, attrs)
+}
+
+// RecordAIBudgetRejection counts a request refused by the project or global budget
+func RecordAIBudgetRejection(ctx context.Context, projectID, scope string) {
+	aiInstruments.once.Do(initAIInstruments)
+	aiInstruments.rejections.Add(ctx, 1, metric.WithAttributes(attribute.String("project", projectID), attribute.String("scope", scope)))
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// CommentThread is a discussion attached to a translation key, either for all
+// languages or for one target language
+type CommentThread struct {
+	ID           string     `json:"id"`
+	ProjectID    string     `json:"project_id"`
+	Key          string     `json:"key"`
+	LanguageCode string     `json:"language_code"` // Empty when the thread applies to all languages
+	Resolved     bool       `json:"resolved"`
+	ResolvedBy   string     `json:"resolved_by,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Comments     []Comment  `json:"comments"`
+}
+
+// Comment is a single message in a thread
+type Comment struct {
+	ID         string    `json:"id"`
+	ThreadID   string    `json:"thread_id"`
+	AuthorType string    `json:"author_type"`
+	AuthorID   string    `json:"author_id"`
+	AuthorName string    `json:"author_name"`
+	Body       string    `json:"body"`
+	Mentions   []string  `json:"mentions"` // Collaborator names mentioned with @name
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Collaborator is a display name chosen by a session or API key in a project
+type Collaborator struct {
+	ProjectID string    `json:"project_id"`
+	AuthorID  string    `json:"author_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
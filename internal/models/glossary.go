@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Glossary is a termbase. Every project has its own glossary; organization
+// glossaries have no project and can be linked to many projects.
+type Glossary struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	ProjectID    string         `json:"project_id,omitempty"` // Empty for organization glossaries
+	SessionToken string         `json:"-"`                    // Owner of an organization glossary
+	CreatedAt    time.Time      `json:"created_at"`
+	Terms        []GlossaryTerm `json:"terms"`
+}
+
+// GlossaryTerm is a source term and how it must be translated
+type GlossaryTerm struct {
+	ID             string                     `json:"id"`
+	GlossaryID     string                     `json:"glossary_id"`
+	Term           string                     `json:"term"`
+	CaseSensitive  bool                       `json:"case_sensitive"`
+	DoNotTranslate bool                       `json:"do_not_translate"` // Term must be kept as is in every language
+	Note           string                     `json:"note,omitempty"`
+	Translations   map[string]TermTranslation `json:"translations"` // By language code
+	CreatedAt      time.Time                  `json:"created_at"`
+}
+
+// TermTranslation is the approved translation of a term in one language
+type TermTranslation struct {
+	Translation string   `json:"translation"`
+	Forbidden   []string `json:"forbidden,omitempty"` // Variants that must not be used
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job types
+const (
+	JobAutoTranslate = "auto_translate"
+	JobTMXImport     = "tmx_import"
+	JobQAReport      = "qa_report"
+	JobQualityReview = "quality_review"
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is a long-running operation executed by a background worker
+type Job struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	ProjectID       string          `json:"project_id,omitempty"`
+	Owner           string          `json:"-"` // Hash of the session that started the job
+	Status          string          `json:"status"`
+	Params          json.RawMessage `json:"-"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	Done            int             `json:"done"`
+	Total           int             `json:"total"`
+	Attempts        int             `json:"attempts"`
+	CancelRequested bool            `json:"cancel_requested"`
+	CreatedAt       time.Time       `json:"created_at"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`
+}
+
+// IsFinished reports whether the job has stopped for good
+func (j *Job) IsFinished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCancelled
+}
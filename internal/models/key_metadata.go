@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// KeyMetadata describes a translation key for translators and the AI
+type KeyMetadata struct {
+	ProjectID      string    `json:"project_id"`
+	Key            string    `json:"key"`
+	Description    string    `json:"description,omitempty"` // Developer description of what the key means
+	Context        string    `json:"context,omitempty"`     // Where the string appears in the UI
+	MaxLength      int       `json:"max_length,omitempty"`  // Maximum character length, 0 means no limit
+	Tags           []string  `json:"tags,omitempty"`
+	Screenshot     string    `json:"screenshot,omitempty"`       // Screenshot URL or reference
+	DoNotTranslate bool      `json:"do_not_translate,omitempty"` // Value must be kept as in the base file
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// IsEmpty reports whether the metadata carries no information
+func (m KeyMetadata) IsEmpty() bool {
+	return m.Description == "" && m.Context == "" && m.MaxLength == 0 &&
+		len(m.Tags) == 0 && m.Screenshot == "" && !m.DoNotTranslate
+}
+
+// Merge returns m with every non-empty field of other applied on top
+func (m KeyMetadata) Merge(other KeyMetadata) KeyMetadata {
+	if other.Description != "" {
+		m.Description = other.Description
+	}
+	if other.Context != "" {
+		m.Context = other.Context
+	}
+	if other.MaxLength > 0 {
+		m.MaxLength = other.MaxLength
+	}
+	if len(other.Tags) > 0 {
+		m.Tags = other.Tags
+	}
+	if other.Screenshot != "" {
+		m.Screenshot = other.Screenshot
+	}
+	if other.DoNotTranslate {
+		m.DoNotTranslate = true
+	}
+	return m
+}
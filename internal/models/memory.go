@@ -0,0 +1,52 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// MemoryEntry is a source/target pair in the translation memory
+type MemoryEntry struct {
+	ID         string    `json:"id"`
+	Owner      string    `json:"-"` // Scope of the entry, see OwnerID and MemoryOwnerID
+	SourceLang string    `json:"source_lang"`
+	TargetLang string    `json:"target_lang"`
+	SourceText string    `json:"source_text"`
+	TargetText string    `json:"target_text"`
+	ProjectID  string    `json:"project_id,omitempty"` // Project the pair was last saved in
+	Origin     string    `json:"origin"`               // Revision source the pair was saved with, or "approved"
+	UseCount   int       `json:"use_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// MemoryMatch is a translation memory entry scored against a lookup text
+type MemoryMatch struct {
+	MemoryEntry
+	Score int `json:"score"` // Similarity in percent, 100 for exact matches
+}
+
+// Memory is an organization translation memory. It belongs to the session
+// that created it and can be linked to many projects.
+type Memory struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	SessionToken string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OwnerID identifies the owner of a session token without exposing the token.
+// Translation memory is shared between all projects with the same owner.
+func OwnerID(sessionToken string) string {
+	if sessionToken == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(sessionToken))
+	return hex.EncodeToString(hash[:])
+}
+
+// MemoryOwnerID is the owner of the entries of an organization memory
+func MemoryOwnerID(memoryID string) string {
+	return "memory:" + memoryID
+}
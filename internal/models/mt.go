@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// MTProvider selects a machine translation provider and, for LLM providers, a model
+type MTProvider struct {
+	Provider string `json:"provider"`        // "openai", "deepl", "google", "libretranslate" or "ollama"
+	Model    string `json:"model,omitempty"` // Empty uses the provider's configured default
+}
+
+// MTSettings configures the machine translation providers of a project. Each
+// provider is tried in order until one succeeds.
+type MTSettings struct {
+	Providers     []MTProvider            `json:"providers,omitempty"`      // Fallback order for all languages
+	Languages     map[string][]MTProvider `json:"languages,omitempty"`      // Fallback order per target language
+	MonthlyBudget float64                 `json:"monthly_budget,omitempty"` // Spending limit in USD per calendar month, 0 for none
+}
+
+// ForLanguage returns the provider fallback order for a target language
+func (s MTSettings) ForLanguage(lang string) []MTProvider {
+	if providers, ok := s.Languages[lang]; ok && len(providers) > 0 {
+		return providers
+	}
+	return s.Providers
+}
+
+// MTCacheEntry is a cached machine translation of a source text
+type MTCacheEntry struct {
+	Key         string    `json:"key"` // Hash of translator, languages, source text and context
+	Provider    string    `json:"provider"`
+	Model       string    `json:"model,omitempty"`
+	SourceLang  string    `json:"source_lang"`
+	TargetLang  string    `json:"target_lang"`
+	SourceText  string    `json:"source_text"`
+	ContextHash string    `json:"context_hash"`
+	Translation string    `json:"translation"`
+	Hits        int       `json:"hits"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
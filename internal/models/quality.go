@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Types of problems found by quality estimation
+const (
+	QualityMeaning     = "meaning"     // The translation drifts from the source
+	QualityFormality   = "formality"   // Wrong form of address for the style guide
+	QualityGrammar     = "grammar"     // Grammar, spelling or punctuation
+	QualityTerminology = "terminology" // Inconsistent or wrong terms
+	QualityOther       = "other"
+)
+
+// LowQualityScore is the score below which a translation counts as low confidence
+const LowQualityScore = 70
+
+// QualityIssue is a problem found in a translation by quality estimation
+type QualityIssue struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// QualityAssessment is an AI review of the translation of a key, a reviewer
+// aid for languages the reviewer cannot read. It applies to the value it was
+// made for only.
+type QualityAssessment struct {
+	FileID          string         `json:"-"`
+	Key             string         `json:"key"`
+	TargetHash      string         `json:"-"`               // Hash of the assessed value
+	Score           *int           `json:"score,omitempty"` // 0-100, nil when only back-translated
+	Issues          []QualityIssue `json:"issues"`
+	BackTranslation string         `json:"back_translation,omitempty"` // The value translated back into the base language
+	Translator      string         `json:"translator"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
+
+// IsLowConfidence reports whether the score is below LowQualityScore
+func (a QualityAssessment) IsLowConfidence() bool {
+	return a.Score != nil && *a.Score < LowQualityScore
+}
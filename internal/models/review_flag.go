@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+const (
+	ReviewSourceChanged = "source_changed"
+	ReviewRenamed       = "renamed"
+)
+
+// ReviewFlag marks a translation whose source text changed after it was translated
+type ReviewFlag struct {
+	ProjectID    string    `json:"project_id"`
+	LanguageCode string    `json:"language_code"`
+	Key          string    `json:"key"`
+	Reason       string    `json:"reason"`     // "source_changed" or "renamed"
+	OldSource    string    `json:"old_source"` // Source text the translation was made from
+	CreatedAt    time.Time `json:"created_at"`
+}
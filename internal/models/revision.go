@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Revision sources
+const (
+	SourceManual     = "manual"
+	SourceImport     = "import"
+	SourceAI         = "ai"
+	SourceRevert     = "revert"
+	SourceRestore    = "restore"     // Restored from a snapshot
+	SourceBaseUpdate = "base_update" // Migrated after the base file was replaced
+	SourceSuggestion = "suggestion"  // Accepted community suggestion
+	SourceMemory     = "memory"      // Filled from translation memory
+)
+
+// Author types
+const (
+	AuthorSession = "session"
+	AuthorAPIKey  = "api_key"
+	AuthorAI      = "ai"
+)
+
+// Revision records a single change to a translation value
+type Revision struct {
+	ID           string    `json:"id"`
+	ProjectID    string    `json:"project_id"`
+	FileID       string    `json:"file_id"`
+	LanguageCode string    `json:"language_code"`
+	Key          string    `json:"key"`
+	OldValue     string    `json:"old_value"`
+	NewValue     string    `json:"new_value"`
+	AuthorType   string    `json:"author_type"`         // "session", "api_key" or "ai"
+	AuthorID     string    `json:"author_id"`           // Session fingerprint, API key ID or model name
+	Source       string    `json:"source"`              // "manual", "import", "ai", "revert", "restore", "base_update", "suggestion" or "memory"
+	BatchID      string    `json:"batch_id"`            // Groups revisions made by one operation
+	SourceHash   string    `json:"source_hash"`         // Hash of the base value the new value was translated from
+	PromptID     string    `json:"prompt_id,omitempty"` // Prompt that produced an AI translation
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RevisionBatch summarizes the revisions made by one operation
+type RevisionBatch struct {
+	BatchID    string    `json:"batch_id"`
+	Source     string    `json:"source"`
+	AuthorType string    `json:"author_type"`
+	AuthorID   string    `json:"author_id"`
+	Keys       int       `json:"keys"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Screenshot is an uploaded image of the application UI
+type Screenshot struct {
+	ID          string             `json:"id"`
+	ProjectID   string             `json:"project_id"`
+	Name        string             `json:"name"`
+	StorageKey  string             `json:"-"` // Object key in the storage backend
+	ContentType string             `json:"content_type"`
+	Width       int                `json:"width"`
+	Height      int                `json:"height"`
+	CreatedAt   time.Time          `json:"created_at"`
+	Regions     []ScreenshotRegion `json:"regions"`
+}
+
+// ScreenshotRegion links a rectangle on a screenshot to a translation key.
+// Coordinates are fractions of the image size (0..1) so they survive resizing.
+type ScreenshotRegion struct {
+	ID           string    `json:"id"`
+	ScreenshotID string    `json:"screenshot_id"`
+	ProjectID    string    `json:"project_id"`
+	Key          string    `json:"key"`
+	X            float64   `json:"x"`
+	Y            float64   `json:"y"`
+	Width        float64   `json:"width"`
+	Height       float64   `json:"height"`
+	CreatedAt    time.Time `json:"created_at"`
+}
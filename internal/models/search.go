@@ -0,0 +1,20 @@
+package models
+
+// SearchHit is a base value and its translation matching a concordance search
+type SearchHit struct {
+	ProjectID   string     `json:"project_id"`
+	ProjectName string     `json:"project_name"`
+	Key         string     `json:"key"`
+	SourceLang  string     `json:"source_lang"`
+	TargetLang  string     `json:"target_lang"`
+	SourceText  string     `json:"source_text"`
+	TargetText  string     `json:"target_text"`
+	SourceSpans []TextSpan `json:"source_spans"`
+	TargetSpans []TextSpan `json:"target_spans"`
+}
+
+// TextSpan is a piece of a search hit, marked when it matched the query
+type TextSpan struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Snapshot is a named, immutable copy of all files of a project
+type Snapshot struct {
+	ID          string         `json:"id"`
+	ProjectID   string         `json:"project_id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Files       []SnapshotFile `json:"files,omitempty"`
+}
+
+// SnapshotFile is one language of a snapshot. Content is stored in
+// deduplicated blobs, so unchanged files are shared between snapshots.
+type SnapshotFile struct {
+	SnapshotID   string `json:"-"`
+	FileType     string `json:"file_type"`
+	LanguageCode string `json:"language_code"`
+	BlobHash     string `json:"blob_hash"`
+	Content      string `json:"-"`
+}
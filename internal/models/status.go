@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// Translation workflow statuses
+const (
+	StatusUntranslated      = "untranslated"
+	StatusDraft             = "draft"
+	StatusMachineTranslated = "machine_translated"
+	StatusTranslated        = "translated"
+	StatusNeedsReview       = "needs_review"
+	StatusApproved          = "approved"
+	StatusRejected          = "rejected"
+)
+
+// Statuses lists all workflow statuses in workflow order
+var Statuses = []string{
+	StatusUntranslated,
+	StatusDraft,
+	StatusMachineTranslated,
+	StatusTranslated,
+	StatusNeedsReview,
+	StatusApproved,
+	StatusRejected,
+}
+
+// Project roles, from least to most privileged
+const (
+	RoleSuggester  = "suggester"  // Callers without the secret key or a key with the "write" permission
+	RoleTranslator = "translator" // The project's secret key or a key with the "write" permission
+	RoleReviewer   = "reviewer"   // API key or share link with the "review" permission
+	RoleOwner      = "owner"      // The session that created the project
+)
+
+// TranslationStatus is the workflow status of one key in one target file
+type TranslationStatus struct {
+	FileID    string    `json:"file_id"`
+	Key       string    `json:"key"`
+	Status    string    `json:"status"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ValidStatus reports whether s is a known workflow status
+func ValidStatus(s string) bool {
+	for _, status := range Statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusForSource returns the status a value gets when it is written by the
+// given revision source
+func StatusForSource(source string) string {
+	if source == SourceAI {
+		return StatusMachineTranslated
+	}
+	return StatusTranslated
+}
+
+// translatorTargets are the statuses a translator may move a translated value to
+var translatorTargets = map[string]bool{
+	StatusDraft:       true,
+	StatusTranslated:  true,
+	StatusNeedsReview: true,
+}
+
+// CanTransition reports whether a role may move a translated value from one
+// status to another. Owners and reviewers may set any status; translators may
+// only move values that are not approved to draft, translated or needs review.
+// Suggesters cannot change statuses.
+func CanTransition(role, from, to string) bool {
+	if !ValidStatus(to) || to == StatusUntranslated || from == to {
+		return false
+	}
+	switch role {
+	case RoleOwner, RoleReviewer:
+		return true
+	case RoleSuggester:
+		return false
+	default:
+		return from != StatusApproved && translatorTargets[to]
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Formality levels of a style guide
+const (
+	FormalityDefault  = ""
+	FormalityFormal   = "formal"   // e.g. German "Sie", Finnish "te", French "vous"
+	FormalityInformal = "informal" // e.g. German "du", Finnish "sinä", French "tu"
+)
+
+// StyleGuide describes how a project's texts should be translated. It is
+// shown to the AI with every translation request.
+type StyleGuide struct {
+	Formality string `json:"formality,omitempty"` // "formal", "informal" or empty
+	Tone      string `json:"tone,omitempty"`      // e.g. "friendly and concise"
+	Audience  string `json:"audience,omitempty"`  // e.g. "developers", "children"
+	Notes     string `json:"notes,omitempty"`     // Any other instructions
+}
+
+// IsEmpty reports whether no guidance is set
+func (s StyleGuide) IsEmpty() bool {
+	return s == StyleGuide{}
+}
+
+// ValidFormality reports whether f is a known formality level
+func ValidFormality(f string) bool {
+	return f == FormalityDefault || f == FormalityFormal || f == FormalityInformal
+}
+
+// MTPrompt is a prompt sent to an LLM provider, recorded for auditing
+type MTPrompt struct {
+	ID         string    `json:"id"`
+	ProjectID  string    `json:"project_id"`
+	BatchID    string    `json:"batch_id"`
+	Translator string    `json:"translator"`
+	Prompt     string    `json:"prompt"`
+	CreatedAt  time.Time `json:"created_at"`
+}
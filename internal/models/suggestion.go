@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Suggestion states
+const (
+	SuggestionOpen      = "open"
+	SuggestionAccepted  = "accepted"
+	SuggestionDismissed = "dismissed"
+)
+
+// Suggestion is a proposed translation for a key, submitted by a contributor
+// without write access and accepted by an owner or reviewer
+type Suggestion struct {
+	ID         string    `json:"id"`
+	ProjectID  string    `json:"project_id"`
+	FileID     string    `json:"file_id"`
+	Key        string    `json:"key"`
+	Value      string    `json:"value"`
+	AuthorType string    `json:"author_type"`
+	AuthorID   string    `json:"author_id"`
+	Status     string    `json:"status"` // "open", "accepted" or "dismissed"
+	Votes      int       `json:"votes"`
+	HasVoted   bool      `json:"has_voted"` // Whether the requesting contributor upvoted it
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// AIUsage is the usage and estimated cost of one machine translation request
+type AIUsage struct {
+	ID               string    `json:"id"`
+	ProjectID        string    `json:"project_id"`
+	JobID            string    `json:"job_id,omitempty"`
+	BatchID          string    `json:"batch_id,omitempty"`
+	AuthorType       string    `json:"author_type"` // Who started the translation: "session" or "api_key"
+	AuthorID         string    `json:"author_id"`
+	Translator       string    `json:"translator"` // e.g. "openai/gpt-4o-mini" or "deepl"
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	Characters       int       `json:"characters"` // Billed characters of non-LLM providers
+	Cost             float64   `json:"cost"`       // Estimated cost in USD
+	Month            string    `json:"month"`      // Billing month, e.g. "2026-10"
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// UsageTotal sums the AI usage of a group, e.g. a project, translator or author
+type UsageTotal struct {
+	Group            string  `json:"group"`
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Characters       int     `json:"characters"`
+	Cost             float64 `json:"cost"`
+}
+
+// Add adds the usage of another group
+func (t *UsageTotal) Add(o UsageTotal) {
+	t.Requests += o.Requests
+	t.PromptTokens += o.PromptTokens
+	t.CompletionTokens += o.CompletionTokens
+	t.Characters += o.Characters
+	t.Cost += o.Cost
+}
+
+// UsageMonth returns the billing month of a time, in UTC
+func UsageMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// ProjectUsage is the AI usage of a project in a month against its budget
+type ProjectUsage struct {
+	ProjectID   string     `json:"project_id"`
+	ProjectName string     `json:"project_name"`
+	Total       UsageTotal `json:"total"`
+	Budget      float64    `json:"budget"` // 0 for none
+}
+
+// UsageOverview is the AI usage of the projects a caller can access
+type UsageOverview struct {
+	Month        string         `json:"month"`
+	Projects     []ProjectUsage `json:"projects"`
+	Total        UsageTotal     `json:"total"`
+	GlobalCost   float64        `json:"global_cost,omitempty"` // Spent by all projects, only shown with a global budget
+	GlobalBudget float64        `json:"global_budget"`
+}
+
+// UsageReport breaks down the AI usage of one project in a month
+type UsageReport struct {
+	ProjectID    string       `json:"project_id"`
+	Month        string       `json:"month"`
+	Budget       float64      `json:"budget"`
+	Total        UsageTotal   `json:"total"`
+	ByTranslator []UsageTotal `json:"by_translator"`
+	ByAuthor     []UsageTotal `json:"by_author"`
+	ByDay        []UsageTotal `json:"by_day"`
+	Recent       []AIUsage    `json:"recent"`
+}
@@ -0,0 +1,254 @@
+package qa
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func init() {
+	Register(entryCheck{"whitespace", "Leading or trailing whitespace differs from source", SeverityWarning, checkWhitespace})
+	Register(entryCheck{"double_space", "Translation contains doubled spaces", SeverityInfo, checkDoubleSpace})
+	Register(entryCheck{"punctuation", "Ending punctuation differs from source", SeverityWarning, checkPunctuation})
+	Register(entryCheck{"numbers", "Numbers differ from source", SeverityWarning, checkNumbers})
+	Register(entryCheck{"urls", "URLs or email addresses differ from source", SeverityError, checkURLs})
+	Register(entryCheck{"max_length", "Translation exceeds the maximum length", SeverityError, checkMaxLength})
+	Register(entryCheck{"identical", "Translation is identical to source", SeverityInfo, checkIdentical})
+	Register(entryCheck{"do_not_translate", "Key marked do-not-translate was changed", SeverityError, checkDoNotTranslate})
+	Register(entryCheck{"untranslated", "Translation looks untranslated", SeverityWarning, checkUntranslated})
+	Register(inconsistencyCheck{})
+	Register(glossaryCheck{})
+}
+
+// entryCheck adapts a per-entry function to the Check interface
+type entryCheck struct {
+	id       string
+	desc     string
+	severity Severity
+	fn       func(e Entry) string
+}
+
+func (c entryCheck) ID() string                { return c.id }
+func (c entryCheck) Description() string       { return c.desc }
+func (c entryCheck) DefaultSeverity() Severity { return c.severity }
+
+func (c entryCheck) Run(entries []Entry) []Finding {
+	var findings []Finding
+	for _, e := range entries {
+		if msg := c.fn(e); msg != "" {
+			findings = append(findings, Finding{Key: e.Key, Message: msg})
+		}
+	}
+	return findings
+}
+
+func checkWhitespace(e Entry) string {
+	baseLead := len(e.Base) - len(strings.TrimLeftFunc(e.Base, unicode.IsSpace))
+	targetLead := len(e.Target) - len(strings.TrimLeftFunc(e.Target, unicode.IsSpace))
+	if (baseLead > 0) != (targetLead > 0) {
+		return "leading whitespace differs from source"
+	}
+	baseTrail := len(e.Base) - len(strings.TrimRightFunc(e.Base, unicode.IsSpace))
+	targetTrail := len(e.Target) - len(strings.TrimRightFunc(e.Target, unicode.IsSpace))
+	if (baseTrail > 0) != (targetTrail > 0) {
+		return "trailing whitespace differs from source"
+	}
+	return ""
+}
+
+func checkDoubleSpace(e Entry) string {
+	if strings.Contains(e.Target, "  ") && !strings.Contains(e.Base, "  ") {
+		return "contains doubled spaces"
+	}
+	return ""
+}
+
+// punctuationClasses maps ending punctuation (including full-width forms) to a common class
+var punctuationClasses = map[rune]rune{
+	'.': '.', '。': '.', '．': '.',
+	'!': '!', '！': '!', '¡': '!',
+	'?': '?', '？': '?', '¿': '?', ';': ';', '；': ';',
+	':': ':', '：': ':',
+	'…': '…',
+	',': ',', '，': ',', '、': ',',
+}
+
+func endingPunctuation(s string) rune {
+	s = strings.TrimRightFunc(s, unicode.IsSpace)
+	if strings.HasSuffix(s, "...") {
+		return '…'
+	}
+	r, _ := utf8.DecodeLastRuneInString(s)
+	if class, ok := punctuationClasses[r]; ok {
+		return class
+	}
+	return 0
+}
+
+func checkPunctuation(e Entry) string {
+	base, target := endingPunctuation(e.Base), endingPunctuation(e.Target)
+	if base == target {
+		return ""
+	}
+	switch {
+	case base == 0:
+		return fmt.Sprintf("ends with %q but source has no ending punctuation", target)
+	case target == 0:
+		return fmt.Sprintf("missing ending punctuation %q", base)
+	default:
+		return fmt.Sprintf("ends with %q but source ends with %q", target, base)
+	}
+}
+
+var numberRegex = regexp.MustCompile(`\d+(?:[.,\x{00A0}\x{202F}]\d+)*`)
+
+// extractNumbers returns the digits of every number, ignoring locale-specific separators
+func extractNumbers(s string) []string {
+	var numbers []string
+	for _, m := range numberRegex.FindAllString(s, -1) {
+		numbers = append(numbers, strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, m))
+	}
+	sort.Strings(numbers)
+	return numbers
+}
+
+func checkNumbers(e Entry) string {
+	base, target := extractNumbers(e.Base), extractNumbers(e.Target)
+	if strings.Join(base, ",") != strings.Join(target, ",") {
+		return fmt.Sprintf("numbers %v do not match source %v", target, base)
+	}
+	return ""
+}
+
+var (
+	urlRegex   = regexp.MustCompile(`https?://[^\s<>"']+[^\s<>"'.,;:!?)]`)
+	emailRegex = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+func checkURLs(e Entry) string {
+	for _, re := range []*regexp.Regexp{urlRegex, emailRegex} {
+		for _, m := range re.FindAllString(e.Base, -1) {
+			if !strings.Contains(e.Target, m) {
+				return fmt.Sprintf("missing %s from source", m)
+			}
+		}
+		for _, m := range re.FindAllString(e.Target, -1) {
+			if !strings.Contains(e.Base, m) {
+				return fmt.Sprintf("%s is not present in source", m)
+			}
+		}
+	}
+	return ""
+}
+
+func checkMaxLength(e Entry) string {
+	if e.MaxLength <= 0 {
+		return ""
+	}
+	if n := utf8.RuneCountInString(e.Target); n > e.MaxLength {
+		return fmt.Sprintf("length %d exceeds maximum of %d characters", n, e.MaxLength)
+	}
+	return ""
+}
+
+func hasLetters(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+func checkIdentical(e Entry) string {
+	if e.Target == e.Base && hasLetters(e.Base) && !e.DoNotTranslate {
+		return "identical to source"
+	}
+	return ""
+}
+
+func checkDoNotTranslate(e Entry) string {
+	if e.DoNotTranslate && e.Target != e.Base {
+		return "key is marked do-not-translate and must match source"
+	}
+	return ""
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// checkUntranslated flags targets that are not identical to the source but
+// still reuse most of its words, e.g. a source sentence with one word changed
+func checkUntranslated(e Entry) string {
+	if e.Target == e.Base || e.DoNotTranslate {
+		return ""
+	}
+	baseWords := words(e.Base)
+	targetWords := words(e.Target)
+	if len(baseWords) < 3 || len(targetWords) == 0 {
+		return ""
+	}
+	known := make(map[string]bool, len(baseWords))
+	for _, w := range baseWords {
+		known[w] = true
+	}
+	shared := 0
+	for _, w := range targetWords {
+		if known[w] {
+			shared++
+		}
+	}
+	if float64(shared)/float64(len(targetWords)) >= 0.8 {
+		return "most words are copied from source"
+	}
+	return ""
+}
+
+// inconsistencyCheck flags keys whose source text is shared with other keys
+// but which were translated differently
+type inconsistencyCheck struct{}
+
+func (inconsistencyCheck) ID() string          { return "inconsistent" }
+func (inconsistencyCheck) Description() string { return "Same source text is translated differently" }
+func (inconsistencyCheck) DefaultSeverity() Severity {
+	return SeverityWarning
+}
+
+func (inconsistencyCheck) Run(entries []Entry) []Finding {
+	bySource := make(map[string][]Entry)
+	for _, e := range entries {
+		if strings.TrimSpace(e.Base) == "" {
+			continue
+		}
+		bySource[e.Base] = append(bySource[e.Base], e)
+	}
+
+	var findings []Finding
+	for _, group := range bySource {
+		variants := make(map[string]bool)
+		for _, e := range group {
+			variants[e.Target] = true
+		}
+		if len(variants) < 2 {
+			continue
+		}
+		list := make([]string, 0, len(variants))
+		for v := range variants {
+			list = append(list, fmt.Sprintf("%q", v))
+		}
+		sort.Strings(list)
+		for _, e := range group {
+			findings = append(findings, Finding{
+				Key:     e.Key,
+				Message: fmt.Sprintf("source %q is translated inconsistently: %s", e.Base, strings.Join(list, ", ")),
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,158 @@
+package qa
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Term is a glossary term as it applies to one target language
+type Term struct {
+	Source         string
+	Translation    string   // Approved translation, empty if none
+	Forbidden      []string // Variants that must not be used
+	CaseSensitive  bool
+	DoNotTranslate bool // Source must appear unchanged in the target
+	Note           string
+}
+
+// TermMatch is an occurrence of a glossary term in a text
+type TermMatch struct {
+	Term  Term
+	Start int // Byte offsets into the text
+	End   int
+}
+
+// FindTerms returns the occurrences of whole-word terms in text ordered by
+// position. Overlapping matches are resolved in favour of the longer term.
+func FindTerms(text string, terms []Term) []TermMatch {
+	return make(wordPatterns).findTerms(text, terms)
+}
+
+// TermFinder is FindTerms for many texts, compiling the pattern of each term once
+type TermFinder struct {
+	terms    []Term
+	patterns wordPatterns
+}
+
+// NewTermFinder creates a TermFinder for the terms
+func NewTermFinder(terms []Term) *TermFinder {
+	return &TermFinder{terms: terms, patterns: make(wordPatterns)}
+}
+
+// Find returns the occurrences of the terms in text, like FindTerms
+func (f *TermFinder) Find(text string) []TermMatch {
+	return f.patterns.findTerms(text, f.terms)
+}
+
+// wordPatterns caches the compiled patterns of words, so a run over many
+// entries compiles each term once
+type wordPatterns map[string]*regexp.Regexp
+
+func (p wordPatterns) findTerms(text string, terms []Term) []TermMatch {
+	var matches []TermMatch
+	for _, term := range terms {
+		for _, loc := range p.findWord(text, term.Source, term.CaseSensitive) {
+			matches = append(matches, TermMatch{Term: term, Start: loc[0], End: loc[1]})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End > matches[j].End
+	})
+
+	result := matches[:0]
+	end := 0
+	for _, m := range matches {
+		if m.Start >= end {
+			result = append(result, m)
+			end = m.End
+		}
+	}
+	return result
+}
+
+// findWord returns the locations of word in text that are not part of a longer word
+func (p wordPatterns) findWord(text, word string, caseSensitive bool) [][]int {
+	if word == "" {
+		return nil
+	}
+	pattern := regexp.QuoteMeta(word)
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, ok := p[pattern]
+	if !ok {
+		re = regexp.MustCompile(pattern)
+		p[pattern] = re
+	}
+	var locs [][]int
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		before, _ := utf8.DecodeLastRuneInString(text[:loc[0]])
+		after, _ := utf8.DecodeRuneInString(text[loc[1]:])
+		if !isWordRune(before) && !isWordRune(after) {
+			locs = append(locs, loc)
+		}
+	}
+	return locs
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func containsTerm(text, term string, caseSensitive bool) bool {
+	if caseSensitive {
+		return strings.Contains(text, term)
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(term))
+}
+
+// glossaryCheck flags target values that ignore the glossary terms used in the source
+type glossaryCheck struct{}
+
+func (glossaryCheck) ID() string                { return "glossary" }
+func (glossaryCheck) Description() string       { return "Translation does not follow the glossary" }
+func (glossaryCheck) DefaultSeverity() Severity { return SeverityWarning }
+
+func (glossaryCheck) Run(entries []Entry) []Finding {
+	var findings []Finding
+	patterns := make(wordPatterns)
+	for _, e := range entries {
+		seen := make(map[string]bool)
+		for _, m := range patterns.findTerms(e.Base, e.Terms) {
+			term := m.Term
+			if seen[term.Source] {
+				continue
+			}
+			seen[term.Source] = true
+
+			if term.DoNotTranslate {
+				if !containsTerm(e.Target, term.Source, term.CaseSensitive) {
+					findings = append(findings, Finding{Key: e.Key, Message: fmt.Sprintf("%q must not be translated", term.Source)})
+				}
+				continue
+			}
+			if term.Translation != "" && !containsTerm(e.Target, term.Translation, term.CaseSensitive) {
+				findings = append(findings, Finding{Key: e.Key, Message: fmt.Sprintf("%q should be translated as %q", term.Source, term.Translation)})
+			}
+
+			for _, variant := range term.Forbidden {
+				if len(patterns.findWord(e.Target, variant, term.CaseSensitive)) == 0 {
+					continue
+				}
+				msg := fmt.Sprintf("%q is a forbidden translation of %q", variant, term.Source)
+				if term.Translation != "" {
+					msg += fmt.Sprintf(", use %q", term.Translation)
+				}
+				findings = append(findings, Finding{Key: e.Key, Message: msg})
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,187 @@
+package qa
+
+import (
+	"sort"
+)
+
+// Severity controls how a QA finding is reported
+type Severity string
+
+const (
+	SeverityOff     Severity = "off"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Valid reports whether s is a known severity
+func (s Severity) Valid() bool {
+	switch s {
+	case SeverityOff, SeverityInfo, SeverityWarning, SeverityError:
+		return true
+	}
+	return false
+}
+
+// Entry is a single translation unit to check
+type Entry struct {
+	Key            string
+	Base           string
+	Target         string
+	MaxLength      int    // 0 means no limit
+	DoNotTranslate bool   // Target must equal base
+	Terms          []Term // Glossary terms for the target language
+}
+
+// Finding is a problem reported by a check for a single key
+type Finding struct {
+	Key     string
+	Message string
+}
+
+// Check is a pluggable QA rule. Checks receive every entry of a file so that
+// cross-key rules (like inconsistency) can be implemented the same way as
+// per-key rules.
+type Check interface {
+	ID() string
+	Description() string
+	DefaultSeverity() Severity
+	Run(entries []Entry) []Finding
+}
+
+// Issue is a finding with the rule and effective severity attached
+type Issue struct {
+	Key      string   `json:"key"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Report is the result of running all enabled checks
+type Report struct {
+	Issues []Issue             `json:"issues"`
+	Counts map[Severity]int    `json:"counts"`
+	Rules  map[string]Severity `json:"rules"`
+}
+
+// HasErrors reports whether any error-level issue was found
+func (r Report) HasErrors() bool {
+	return r.Counts[SeverityError] > 0
+}
+
+// ForKey returns the issues reported for a single key
+func (r Report) ForKey(key string) []Issue {
+	var issues []Issue
+	for _, issue := range r.Issues {
+		if issue.Key == key {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// ByKey groups issues by translation key
+func (r Report) ByKey() map[string][]Issue {
+	grouped := make(map[string][]Issue)
+	for _, issue := range r.Issues {
+		grouped[issue.Key] = append(grouped[issue.Key], issue)
+	}
+	return grouped
+}
+
+// Config maps rule IDs to the severity configured for a project.
+// Rules not present fall back to their default severity.
+type Config map[string]Severity
+
+// Severity returns the effective severity of a check under this config
+func (c Config) Severity(check Check) Severity {
+	if s, ok := c[check.ID()]; ok && s.Valid() {
+		return s
+	}
+	return check.DefaultSeverity()
+}
+
+// ParseConfig converts stored rule settings into a Config, ignoring unknown
+// rules and invalid severities
+func ParseConfig(rules map[string]string) Config {
+	cfg := make(Config)
+	for id, sev := range rules {
+		s := Severity(sev)
+		if _, ok := registry[id]; ok && s.Valid() {
+			cfg[id] = s
+		}
+	}
+	return cfg
+}
+
+var registry = make(map[string]Check)
+
+// Register adds a check to the global registry. Registering a check with an
+// existing ID replaces it.
+func Register(c Check) {
+	registry[c.ID()] = c
+}
+
+// Checks returns all registered checks sorted by ID
+func Checks() []Check {
+	checks := make([]Check, 0, len(registry))
+	for _, c := range registry {
+		checks = append(checks, c)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].ID() < checks[j].ID() })
+	return checks
+}
+
+// Lookup returns a registered check by ID
+func Lookup(id string) (Check, bool) {
+	c, ok := registry[id]
+	return c, ok
+}
+
+// Run executes every enabled check against the entries.
+// Entries with an empty target are skipped since they are reported as missing elsewhere.
+func Run(entries []Entry, cfg Config) Report {
+	translated := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if e.Target != "" {
+			translated = append(translated, e)
+		}
+	}
+
+	report := Report{
+		Issues: []Issue{},
+		Counts: make(map[Severity]int),
+		Rules:  make(map[string]Severity),
+	}
+	for _, check := range Checks() {
+		sev := cfg.Severity(check)
+		report.Rules[check.ID()] = sev
+		if sev == SeverityOff {
+			continue
+		}
+		for _, f := range check.Run(translated) {
+			report.Issues = append(report.Issues, Issue{
+				Key:      f.Key,
+				Rule:     check.ID(),
+				Severity: sev,
+				Message:  f.Message,
+			})
+			report.Counts[sev]++
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return report.Issues[i].Key < report.Issues[j].Key
+	})
+	return report
+}
+
+// EntriesFromFlat builds entries from flattened base and target maps
+func EntriesFromFlat(base, target map[string]string) []Entry {
+	entries := make([]Entry, 0, len(base))
+	for key, baseVal := range base {
+		entries = append(entries, Entry{Key: key, Base: baseVal, Target: target[key]})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
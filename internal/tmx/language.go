@@ -0,0 +1,50 @@
+package tmx
+
+import "strings"
+
+// NormalizeLanguage returns a language tag in canonical BCP 47 casing, e.g.
+// "EN_us" becomes "en-US" and "zh-hant-tw" becomes "zh-Hant-TW"
+func NormalizeLanguage(tag string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+	for i, p := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(p)
+		case len(p) == 4 && isAlpha(p):
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:]) // Script
+		case len(p) == 2 && isAlpha(p), len(p) == 3 && !isAlpha(p):
+			parts[i] = strings.ToUpper(p) // Region
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// MatchLanguage maps a language tag onto one of the given tags. An exact match
+// wins, then a tag with the same primary language (so "en-US" maps to "en",
+// and "de" to "de-DE"). Without a match the normalized tag is returned.
+func MatchLanguage(tag string, tags []string) string {
+	normalized := NormalizeLanguage(tag)
+	for _, t := range tags {
+		if NormalizeLanguage(t) == normalized {
+			return t
+		}
+	}
+	primary := strings.SplitN(normalized, "-", 2)[0]
+	for _, t := range tags {
+		if strings.SplitN(NormalizeLanguage(t), "-", 2)[0] == primary {
+			return t
+		}
+	}
+	return normalized
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+// Package tmx reads and writes translation memory in the TMX 1.4 exchange format.
+package tmx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Unit is a translation unit: the same segment in several languages
+type Unit struct {
+	Segments map[string]string // Segment text by language tag, with inline codes restored
+}
+
+// Document is a parsed TMX file
+type Document struct {
+	SourceLang string // From the header, may be "*all*" or empty
+	Units      []Unit
+}
+
+// Parse reads a TMX document. Inline codes (bpt, ept, ph, it) are replaced by
+// the native code they wrap, so "<bpt i="1">&lt;b&gt;</bpt>" becomes "<b>".
+func Parse(r io.Reader) (*Document, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+
+	doc := &Document{}
+	var unit *Unit
+	var lang string
+	var seg *strings.Builder
+	root := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid TMX: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case seg != nil:
+				// Inline elements inside a segment only contribute their text
+			case t.Name.Local == "tmx":
+				root = true
+			case t.Name.Local == "header":
+				doc.SourceLang = attr(t, "srclang")
+			case t.Name.Local == "tu":
+				unit = &Unit{Segments: make(map[string]string)}
+			case t.Name.Local == "tuv":
+				lang = attr(t, "lang") // xml:lang in TMX 1.4, lang in 1.1
+			case t.Name.Local == "seg" && unit != nil:
+				seg = &strings.Builder{}
+			}
+		case xml.CharData:
+			if seg != nil {
+				seg.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "seg":
+				if seg != nil && unit != nil && lang != "" {
+					unit.Segments[lang] = seg.String()
+				}
+				seg = nil
+			case "tu":
+				if unit != nil && len(unit.Segments) > 0 {
+					doc.Units = append(doc.Units, *unit)
+				}
+				unit = nil
+			}
+		}
+	}
+	if !root {
+		return nil, fmt.Errorf("invalid TMX: missing <tmx> root element")
+	}
+	return doc, nil
+}
+
+func attr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Write writes units as a TMX 1.4 document. Markup tags and {placeholders} in
+// segments are written as inline codes so other tools protect them.
+func Write(w io.Writer, sourceLang string, units []Unit) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<tmx version="1.4">` + "\n")
+	fmt.Fprintf(&buf, `  <header creationtool="templui" creationtoolversion="1" datatype="plaintext" segtype="sentence" adminlang="en" srclang="%s" o-tmf="templui" creationdate="%s"/>`+"\n",
+		escape(sourceLang), time.Now().UTC().Format("20060102T150405Z"))
+	buf.WriteString("  <body>\n")
+	for _, u := range units {
+		buf.WriteString("    <tu>\n")
+		// Source language first, then the others in a stable order
+		for _, lang := range orderedLangs(u.Segments, sourceLang) {
+			fmt.Fprintf(&buf, `      <tuv xml:lang="%s"><seg>%s</seg></tuv>`+"\n", escape(lang), encodeSegment(u.Segments[lang]))
+		}
+		buf.WriteString("    </tu>\n")
+	}
+	buf.WriteString("  </body>\n</tmx>\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func orderedLangs(segments map[string]string, sourceLang string) []string {
+	langs := make([]string, 0, len(segments))
+	if _, ok := segments[sourceLang]; ok {
+		langs = append(langs, sourceLang)
+	}
+	var rest []string
+	for lang := range segments {
+		if lang != sourceLang {
+			rest = append(rest, lang)
+		}
+	}
+	sort.Strings(rest)
+	return append(langs, rest...)
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// inlineRegex matches markup tags and {placeholders}
+var inlineRegex = regexp.MustCompile(`<(/?)([A-Za-z][A-Za-z0-9:_-]*)[^<>]*?(/?)>|\{[^}]+\}`)
+
+// encodeSegment escapes a segment and wraps its inline codes in bpt/ept pairs,
+// or ph and it elements when a tag has no partner
+func encodeSegment(text string) string {
+	matches := inlineRegex.FindAllStringSubmatchIndex(text, -1)
+
+	// Pair opening and closing tags so they can share an i attribute
+	kinds := make([]string, len(matches))
+	ids := make([]int, len(matches))
+	type open struct {
+		name  string
+		index int
+	}
+	var stack []open
+	next := 1
+	for n, m := range matches {
+		kinds[n] = "ph"
+		if m[2] < 0 || text[m[6]:m[7]] == "/" {
+			continue // Placeholder or self-closing tag
+		}
+		name := strings.ToLower(text[m[4]:m[5]])
+		if text[m[2]:m[3]] == "" {
+			stack = append(stack, open{name, n})
+			kinds[n] = "it-begin"
+			continue
+		}
+		kinds[n] = "it-end"
+		for s := len(stack) - 1; s >= 0; s-- {
+			if stack[s].name == name {
+				kinds[stack[s].index], kinds[n] = "bpt", "ept"
+				ids[stack[s].index], ids[n] = next, next
+				next++
+				stack = stack[:s]
+				break
+			}
+		}
+	}
+
+	var sb strings.Builder
+	pos := 0
+	for n, m := range matches {
+		sb.WriteString(escape(text[pos:m[0]]))
+		code := escape(text[m[0]:m[1]])
+		switch kinds[n] {
+		case "bpt", "ept":
+			fmt.Fprintf(&sb, `<%s i="%d">%s</%s>`, kinds[n], ids[n], code, kinds[n])
+		case "it-begin":
+			fmt.Fprintf(&sb, `<it pos="begin">%s</it>`, code)
+		case "it-end":
+			fmt.Fprintf(&sb, `<it pos="end">%s</it>`, code)
+		default:
+			fmt.Fprintf(&sb, `<ph>%s</ph>`, code)
+		}
+		pos = m[1]
+	}
+	sb.WriteString(escape(text[pos:]))
+	return sb.String()
+}
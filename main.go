@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
@@ -13,8 +15,10 @@ import (
 	"templui/assets"
 	"templui/internal/database"
 	"templui/internal/handlers"
+	"templui/internal/jobs"
 	"templui/internal/metrics"
 	"templui/internal/session"
+	"templui/internal/storage"
 	"templui/migrations"
 )
 
@@ -32,6 +36,23 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Object storage for uploads (local filesystem unless STORAGE_BACKEND=s3)
+	store, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Background jobs (Auto Translate, TMX imports, QA reports)
+	workers, _ := strconv.Atoi(os.Getenv("JOB_WORKERS"))
+	if workers <= 0 {
+		workers = 2
+	}
+	queue := jobs.NewQueue(db, workers)
+	handlers.RegisterJobs(queue, db)
+	if err := queue.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
 	mPort := os.Getenv("METRICS_PORT")
 	if mPort == "" {
 		log.Fatal("failed to find metrics port")
@@ -67,8 +88,24 @@ func main() {
 
 	// Initialize handlers
 	homeHandler := handlers.NewHomeHandler(db)
-	projectHandler := handlers.NewProjectHandler(db)
+	projectHandler := handlers.NewProjectHandler(db, queue)
 	editorHandler := handlers.NewEditorHandler(db)
+	qaHandler := handlers.NewQAHandler(db, queue)
+	metadataHandler := handlers.NewMetadataHandler(db)
+	screenshotHandler := handlers.NewScreenshotHandler(db, store)
+	historyHandler := handlers.NewHistoryHandler(db)
+	snapshotHandler := handlers.NewSnapshotHandler(db)
+	workflowHandler := handlers.NewWorkflowHandler(db)
+	suggestionHandler := handlers.NewSuggestionHandler(db)
+	commentHandler := handlers.NewCommentHandler(db)
+	glossaryHandler := handlers.NewGlossaryHandler(db)
+	memoryHandler := handlers.NewMemoryHandler(db, queue)
+	searchHandler := handlers.NewSearchHandler(db)
+	mtHandler := handlers.NewMTHandler(db)
+	jobHandler := handlers.NewJobHandler(db)
+	eventHandler := handlers.NewEventHandler(db)
+	usageHandler := handlers.NewUsageHandler(db)
+	qualityHandler := handlers.NewQualityHandler(db, queue)
 	exampleHandler := handlers.NewExampleHandler()
 
 	// ── Routes ──────────────────────────────────────────────────────
@@ -80,20 +117,126 @@ func main() {
 	e.GET("/project/:id/edit", editorHandler.Editor)
 	e.GET("/project/:id/auth", editorHandler.ProjectAuth)
 	e.POST("/project/:id/auth", editorHandler.VerifyProjectKey)
+	e.GET("/project/:id/screenshots", screenshotHandler.Screenshots)
+	e.GET("/search", searchHandler.SearchPage)
+	e.GET("/usage", usageHandler.UsagePage)
 
 	// API Routes
 	api := e.Group("/api")
 	{
 		api.POST("/project", projectHandler.CreateProject)
 		api.GET("/project/:id/diff", projectHandler.GetDiff)
+		api.GET("/project/:id/field", projectHandler.GetField)
+		api.GET("/project/:id/fields", projectHandler.GetFields)
 		api.POST("/project/:id/translations", projectHandler.UpdateTranslation)
 		api.POST("/project/:id/translate", projectHandler.AutoTranslate)
+		api.GET("/project/:id/mt", mtHandler.GetSettings)
+		api.POST("/project/:id/mt", mtHandler.UpdateSettings)
+		api.GET("/project/:id/style", mtHandler.GetStyleGuide)
+		api.POST("/project/:id/style", mtHandler.UpdateStyleGuide)
 		api.GET("/project/:id/export", projectHandler.ExportFile)
 
+		// QA
+		api.GET("/qa/rules", qaHandler.ListRules)
+		api.GET("/project/:id/qa", qaHandler.GetReport)
+		api.POST("/project/:id/qa/rules", qaHandler.UpdateRules)
+		api.POST("/project/:id/qa/jobs", qaHandler.QueueReport)
+		api.GET("/project/:id/quality", qualityHandler.GetAssessments)
+		api.POST("/project/:id/quality", qualityHandler.QueueReview)
+
+		// Key metadata
+		api.GET("/project/:id/metadata", metadataHandler.GetMetadata)
+		api.POST("/project/:id/metadata", metadataHandler.UpdateMetadata)
+		api.POST("/project/:id/metadata/import", metadataHandler.ImportMetadata)
+
+		// History
+		api.GET("/project/:id/history", historyHandler.KeyHistory)
+		api.POST("/project/:id/history/:rid/revert", historyHandler.RevertRevision)
+		api.GET("/project/:id/batches", historyHandler.Batches)
+		api.GET("/project/:id/prompts/:pid", historyHandler.Prompt)
+		api.POST("/project/:id/batches/:bid/revert", historyHandler.RevertBatch)
+
+		// Workflow
+		api.GET("/project/:id/statuses", workflowHandler.Statuses)
+		api.POST("/project/:id/status", workflowHandler.SetStatus)
+		api.POST("/project/:id/keys", workflowHandler.CreateKey)
+
+		// Suggestions
+		api.GET("/project/:id/suggestions", suggestionHandler.ListSuggestions)
+		api.POST("/project/:id/suggestions", suggestionHandler.CreateSuggestion)
+		api.POST("/project/:id/suggestions/:sid/vote", suggestionHandler.VoteSuggestion)
+		api.POST("/project/:id/suggestions/:sid/accept", suggestionHandler.AcceptSuggestion)
+		api.POST("/project/:id/suggestions/:sid/dismiss", suggestionHandler.DismissSuggestion)
+
+		// Comments
+		api.GET("/project/:id/comments", commentHandler.ListComments)
+		api.POST("/project/:id/comments", commentHandler.CreateComment)
+		api.POST("/project/:id/comments/:tid/resolve", commentHandler.ResolveThread)
+		api.POST("/project/:id/comments/:tid/reopen", commentHandler.ReopenThread)
+		api.GET("/project/:id/collaborators", commentHandler.ListCollaborators)
+		api.PUT("/project/:id/collaborators/me", commentHandler.SetCollaboratorName)
+
+		// Glossaries
+		api.GET("/project/:id/glossary", glossaryHandler.GetGlossary)
+		api.POST("/project/:id/glossary/terms", glossaryHandler.CreateProjectTerm)
+		api.PUT("/project/:id/glossary/terms/:tid", glossaryHandler.UpdateProjectTerm)
+		api.DELETE("/project/:id/glossary/terms/:tid", glossaryHandler.DeleteProjectTerm)
+		api.POST("/project/:id/glossaries", glossaryHandler.LinkGlossary)
+		api.POST("/project/:id/glossaries/:gid", glossaryHandler.LinkGlossary)
+		api.DELETE("/project/:id/glossaries/:gid", glossaryHandler.UnlinkGlossary)
+		api.POST("/glossaries", glossaryHandler.CreateGlossary)
+		api.GET("/glossaries/:gid", glossaryHandler.GetOrganizationGlossary)
+		api.POST("/glossaries/:gid/terms", glossaryHandler.CreateTerm)
+		api.PUT("/glossaries/:gid/terms/:tid", glossaryHandler.UpdateTerm)
+		api.DELETE("/glossaries/:gid/terms/:tid", glossaryHandler.DeleteTerm)
+
+		// Translation memory
+		api.GET("/project/:id/memory", memoryHandler.Lookup)
+		api.POST("/project/:id/memory/:mid/apply", memoryHandler.Apply)
+		api.POST("/project/:id/pretranslate", memoryHandler.Pretranslate)
+		api.POST("/memory/import", memoryHandler.ImportTMX)
+		api.GET("/memory/export", memoryHandler.ExportTMX)
+		api.GET("/project/:id/memories", memoryHandler.LinkedMemories)
+		api.POST("/project/:id/memories/:mid", memoryHandler.LinkMemory)
+		api.DELETE("/project/:id/memories/:mid", memoryHandler.UnlinkMemory)
+		api.POST("/memories", memoryHandler.CreateMemory)
+		api.GET("/memories/:mid", memoryHandler.GetOrganizationMemory)
+
+		// Background jobs
+		api.GET("/project/:id/jobs", jobHandler.ListProjectJobs)
+		api.GET("/jobs/:jid", jobHandler.GetJob)
+		api.POST("/jobs/:jid/cancel", jobHandler.CancelJob)
+
+		// Live events
+		api.GET("/project/:id/events", eventHandler.Stream)
+
+		// Concordance search
+		api.GET("/search", searchHandler.Search)
+
+		// AI usage
+		api.GET("/usage", usageHandler.Overview)
+		api.GET("/project/:id/usage", usageHandler.ProjectUsage)
+
+		// Snapshots
+		api.GET("/project/:id/snapshots", snapshotHandler.ListSnapshots)
+		api.POST("/project/:id/snapshots", snapshotHandler.CreateSnapshot)
+		api.GET("/project/:id/snapshots/diff", snapshotHandler.DiffSnapshots)
+		api.POST("/project/:id/snapshots/:sid/restore", snapshotHandler.RestoreSnapshot)
+
+		// Screenshots
+		api.GET("/project/:id/screenshots", screenshotHandler.ListScreenshots)
+		api.POST("/project/:id/screenshots", screenshotHandler.UploadScreenshot)
+		api.GET("/project/:id/screenshots/:sid/image", screenshotHandler.ScreenshotImage)
+		api.DELETE("/project/:id/screenshots/:sid", screenshotHandler.DeleteScreenshot)
+		api.POST("/project/:id/screenshots/:sid/regions", screenshotHandler.CreateRegion)
+		api.DELETE("/project/:id/screenshots/:sid/regions/:rid", screenshotHandler.DeleteRegion)
+
 		// Session/User routes
 		api.GET("/user/projects", projectHandler.GetUserProjects)
 		api.GET("/user/templates", projectHandler.GetBaseTemplates)
 		api.GET("/project/:id/base", projectHandler.GetProjectBaseFile)
+		api.POST("/project/:id/base", projectHandler.UpdateBaseFile)
+		api.POST("/project/:id/base/preview", projectHandler.PreviewBaseUpdate)
 	}
 
 	port := os.Getenv("PORT")
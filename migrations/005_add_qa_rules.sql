@@ -0,0 +1,6 @@
+-- +goose Up
+-- Per-project QA rule severities stored as JSON ({"rule_id": "severity"})
+ALTER TABLE projects ADD COLUMN qa_rules TEXT;
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN qa_rules;
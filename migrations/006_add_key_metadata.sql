@@ -0,0 +1,18 @@
+-- +goose Up
+-- Per-key metadata shown to translators and included in AI prompts
+CREATE TABLE key_metadata (
+    project_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    context TEXT NOT NULL DEFAULT '',
+    max_length INTEGER NOT NULL DEFAULT 0,
+    tags TEXT NOT NULL DEFAULT '', -- JSON array since 025_store_key_tags_as_json
+    screenshot TEXT NOT NULL DEFAULT '',
+    do_not_translate BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, key),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE key_metadata;
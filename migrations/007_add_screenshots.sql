@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE screenshots (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    storage_key TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_screenshots_project ON screenshots(project_id);
+
+-- Rectangles on a screenshot linked to translation keys, stored as fractions of the image size
+CREATE TABLE screenshot_regions (
+    id TEXT PRIMARY KEY,
+    screenshot_id TEXT NOT NULL,
+    project_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    x REAL NOT NULL,
+    y REAL NOT NULL,
+    width REAL NOT NULL,
+    height REAL NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (screenshot_id) REFERENCES screenshots(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_screenshot_regions_screenshot ON screenshot_regions(screenshot_id);
+CREATE INDEX idx_screenshot_regions_key ON screenshot_regions(project_id, key);
+
+-- +goose Down
+DROP INDEX idx_screenshot_regions_key;
+DROP INDEX idx_screenshot_regions_screenshot;
+DROP TABLE screenshot_regions;
+DROP INDEX idx_screenshots_project;
+DROP TABLE screenshots;
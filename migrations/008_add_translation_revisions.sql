@@ -0,0 +1,26 @@
+-- +goose Up
+-- Every change to a translation value, so edits can be audited and rolled back
+CREATE TABLE translation_revisions (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    file_id TEXT NOT NULL,
+    language_code TEXT NOT NULL,
+    key TEXT NOT NULL,
+    old_value TEXT NOT NULL,
+    new_value TEXT NOT NULL,
+    author_type TEXT NOT NULL, -- 'session', 'api_key' or 'ai'
+    author_id TEXT NOT NULL,
+    source TEXT NOT NULL, -- 'manual', 'import', 'ai' or 'revert'
+    batch_id TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_revisions_key ON translation_revisions(file_id, key, created_at);
+CREATE INDEX idx_revisions_batch ON translation_revisions(batch_id);
+CREATE INDEX idx_revisions_project ON translation_revisions(project_id, created_at);
+
+-- +goose Down
+DROP INDEX idx_revisions_project;
+DROP INDEX idx_revisions_batch;
+DROP INDEX idx_revisions_key;
+DROP TABLE translation_revisions;
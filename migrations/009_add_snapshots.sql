@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE snapshots (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+-- Content-addressed, gzip-compressed file contents shared by all snapshots
+CREATE TABLE snapshot_blobs (
+    hash TEXT PRIMARY KEY, -- sha256 of the uncompressed content
+    content BLOB NOT NULL,
+    size INTEGER NOT NULL
+);
+
+CREATE TABLE snapshot_files (
+    snapshot_id TEXT NOT NULL,
+    file_type TEXT NOT NULL,
+    language_code TEXT NOT NULL,
+    blob_hash TEXT NOT NULL,
+    PRIMARY KEY (snapshot_id, language_code),
+    FOREIGN KEY (snapshot_id) REFERENCES snapshots(id) ON DELETE CASCADE,
+    FOREIGN KEY (blob_hash) REFERENCES snapshot_blobs(hash)
+);
+
+-- +goose Down
+DROP TABLE snapshot_files;
+DROP TABLE snapshot_blobs;
+DROP TABLE snapshots;
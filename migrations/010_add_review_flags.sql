@@ -0,0 +1,15 @@
+-- +goose Up
+-- Translations that need review because their source text changed
+CREATE TABLE review_flags (
+    project_id TEXT NOT NULL,
+    language_code TEXT NOT NULL,
+    key TEXT NOT NULL,
+    reason TEXT NOT NULL, -- "source_changed" or "renamed"
+    old_source TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, language_code, key),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE review_flags;
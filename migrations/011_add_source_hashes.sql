@@ -0,0 +1,16 @@
+-- +goose Up
+-- Hash of the base value each translation was made from, to detect outdated translations
+ALTER TABLE translation_revisions ADD COLUMN source_hash TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE translation_sources (
+    file_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    source_hash TEXT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (file_id, key),
+    FOREIGN KEY (file_id) REFERENCES files(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE translation_sources;
+ALTER TABLE translation_revisions DROP COLUMN source_hash;
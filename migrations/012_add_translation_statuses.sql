@@ -0,0 +1,15 @@
+-- +goose Up
+-- Workflow status per key and target file. Keys without a row are "translated"
+-- when they have a value and "untranslated" otherwise.
+CREATE TABLE translation_statuses (
+    file_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    status TEXT NOT NULL,
+    updated_by TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (file_id, key),
+    FOREIGN KEY (file_id) REFERENCES files(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE translation_statuses;
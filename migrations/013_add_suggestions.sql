@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE suggestions (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    file_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    author_type TEXT NOT NULL,
+    author_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open', -- 'open', 'accepted' or 'dismissed'
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (file_id) REFERENCES files(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_suggestions_key ON suggestions(file_id, key);
+
+CREATE TABLE suggestion_votes (
+    suggestion_id TEXT NOT NULL,
+    voter_id TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (suggestion_id, voter_id),
+    FOREIGN KEY (suggestion_id) REFERENCES suggestions(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE suggestion_votes;
+DROP INDEX idx_suggestions_key;
+DROP TABLE suggestions;
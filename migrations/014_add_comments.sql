@@ -0,0 +1,50 @@
+-- +goose Up
+CREATE TABLE collaborators (
+    project_id TEXT NOT NULL,
+    author_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, author_id),
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE TABLE comment_threads (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    language_code TEXT NOT NULL DEFAULT '', -- empty for all languages
+    resolved BOOLEAN NOT NULL DEFAULT FALSE,
+    resolved_by TEXT NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_comment_threads_key ON comment_threads(project_id, key);
+
+CREATE TABLE comments (
+    id TEXT PRIMARY KEY,
+    thread_id TEXT NOT NULL,
+    author_type TEXT NOT NULL,
+    author_id TEXT NOT NULL,
+    author_name TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (thread_id) REFERENCES comment_threads(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_comments_thread ON comments(thread_id, created_at);
+
+CREATE TABLE comment_mentions (
+    comment_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    PRIMARY KEY (comment_id, name),
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE comment_mentions;
+DROP INDEX idx_comments_thread;
+DROP TABLE comments;
+DROP INDEX idx_comment_threads_key;
+DROP TABLE comment_threads;
+DROP TABLE collaborators;
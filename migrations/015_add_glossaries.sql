@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE glossaries (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    project_id TEXT UNIQUE, -- set for a project's own glossary, NULL for organization glossaries
+    session_token TEXT NOT NULL DEFAULT '', -- owner of an organization glossary
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+-- Organization glossaries used by a project
+CREATE TABLE project_glossaries (
+    project_id TEXT NOT NULL,
+    glossary_id TEXT NOT NULL,
+    PRIMARY KEY (project_id, glossary_id),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (glossary_id) REFERENCES glossaries(id) ON DELETE CASCADE
+);
+
+CREATE TABLE glossary_terms (
+    id TEXT PRIMARY KEY,
+    glossary_id TEXT NOT NULL,
+    term TEXT NOT NULL,
+    case_sensitive BOOLEAN NOT NULL DEFAULT FALSE,
+    do_not_translate BOOLEAN NOT NULL DEFAULT FALSE,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (glossary_id, term),
+    FOREIGN KEY (glossary_id) REFERENCES glossaries(id) ON DELETE CASCADE
+);
+
+CREATE TABLE glossary_translations (
+    term_id TEXT NOT NULL,
+    language_code TEXT NOT NULL,
+    translation TEXT NOT NULL DEFAULT '',
+    forbidden TEXT NOT NULL DEFAULT '[]', -- JSON array of variants
+    PRIMARY KEY (term_id, language_code),
+    FOREIGN KEY (term_id) REFERENCES glossary_terms(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE glossary_translations;
+DROP TABLE glossary_terms;
+DROP TABLE project_glossaries;
+DROP TABLE glossaries;
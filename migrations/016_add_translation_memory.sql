@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE translation_memory (
+    id TEXT PRIMARY KEY,
+    owner TEXT NOT NULL, -- hash of the owning session token
+    source_lang TEXT NOT NULL,
+    target_lang TEXT NOT NULL,
+    source_text TEXT NOT NULL,
+    target_text TEXT NOT NULL,
+    source_length INTEGER NOT NULL, -- in characters, to narrow fuzzy lookups
+    project_id TEXT NOT NULL DEFAULT '',
+    origin TEXT NOT NULL DEFAULT '',
+    use_count INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (owner, source_lang, target_lang, source_text, target_text)
+);
+CREATE INDEX idx_translation_memory_lookup ON translation_memory(owner, source_lang, target_lang, source_length);
+
+-- +goose Down
+DROP INDEX idx_translation_memory_lookup;
+DROP TABLE translation_memory;
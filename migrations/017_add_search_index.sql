@@ -0,0 +1,49 @@
+-- +goose Up
+-- One row per target file and key with the base value and its translation
+CREATE TABLE search_segments (
+    id INTEGER PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    file_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    source_lang TEXT NOT NULL,
+    target_lang TEXT NOT NULL,
+    source_text TEXT NOT NULL,
+    target_text TEXT NOT NULL
+);
+CREATE INDEX idx_search_segments_file ON search_segments(file_id);
+
+CREATE VIRTUAL TABLE search_index USING fts5(
+    source_text,
+    target_text,
+    content = 'search_segments',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER search_segments_insert AFTER INSERT ON search_segments BEGIN
+    INSERT INTO search_index (rowid, source_text, target_text) VALUES (new.id, new.source_text, new.target_text);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER search_segments_delete AFTER DELETE ON search_segments BEGIN
+    INSERT INTO search_index (search_index, rowid, source_text, target_text) VALUES ('delete', old.id, old.source_text, old.target_text);
+END;
+-- +goose StatementEnd
+
+-- Versions of the base and target file each target file was indexed from
+CREATE TABLE search_index_files (
+    file_id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    target_updated_at TIMESTAMP,
+    base_updated_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE search_index_files;
+DROP TRIGGER search_segments_delete;
+DROP TRIGGER search_segments_insert;
+DROP TABLE search_index;
+DROP INDEX idx_search_segments_file;
+DROP TABLE search_segments;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Per-project machine translation providers stored as JSON (models.MTSettings)
+ALTER TABLE projects ADD COLUMN mt_settings TEXT;
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN mt_settings;
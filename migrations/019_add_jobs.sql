@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE jobs (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    project_id TEXT NOT NULL DEFAULT '',
+    owner TEXT NOT NULL DEFAULT '', -- hash of the session token that started the job
+    status TEXT NOT NULL DEFAULT 'queued',
+    params TEXT NOT NULL DEFAULT '{}',
+    result TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    progress_done INTEGER NOT NULL DEFAULT 0,
+    progress_total INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    cancel_requested BOOLEAN NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_jobs_status ON jobs(status, created_at);
+CREATE INDEX idx_jobs_project ON jobs(project_id, created_at);
+
+-- +goose Down
+DROP INDEX idx_jobs_project;
+DROP INDEX idx_jobs_status;
+DROP TABLE jobs;
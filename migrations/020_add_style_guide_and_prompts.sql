@@ -0,0 +1,23 @@
+-- +goose Up
+-- Project style guide for translators and AI prompts, stored as JSON (models.StyleGuide)
+ALTER TABLE projects ADD COLUMN style_guide TEXT;
+
+-- Prompts sent to LLM providers, kept for auditing machine translations
+CREATE TABLE mt_prompts (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    batch_id TEXT NOT NULL DEFAULT '',
+    translator TEXT NOT NULL,
+    prompt TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_mt_prompts_batch ON mt_prompts(batch_id);
+
+ALTER TABLE translation_revisions ADD COLUMN prompt_id TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE translation_revisions DROP COLUMN prompt_id;
+DROP INDEX idx_mt_prompts_batch;
+DROP TABLE mt_prompts;
+ALTER TABLE projects DROP COLUMN style_guide;
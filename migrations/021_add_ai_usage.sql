@@ -0,0 +1,25 @@
+-- +goose Up
+-- Usage and estimated cost of every request to a machine translation provider.
+-- Rows are kept when a project is deleted so the global budget stays accurate.
+CREATE TABLE ai_usage (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL,
+    job_id TEXT NOT NULL DEFAULT '',
+    batch_id TEXT NOT NULL DEFAULT '',
+    author_type TEXT NOT NULL DEFAULT '',
+    author_id TEXT NOT NULL DEFAULT '',
+    translator TEXT NOT NULL,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    characters INTEGER NOT NULL DEFAULT 0,
+    cost REAL NOT NULL DEFAULT 0,
+    month TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_ai_usage_project_month ON ai_usage(project_id, month);
+CREATE INDEX idx_ai_usage_month ON ai_usage(month);
+
+-- +goose Down
+DROP INDEX idx_ai_usage_month;
+DROP INDEX idx_ai_usage_project_month;
+DROP TABLE ai_usage;
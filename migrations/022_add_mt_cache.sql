@@ -0,0 +1,21 @@
+-- +goose Up
+-- Machine translations shared by all projects, reused for identical source
+-- texts with the same prompt context until they expire
+CREATE TABLE mt_cache (
+    cache_key TEXT PRIMARY KEY,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    source_lang TEXT NOT NULL,
+    target_lang TEXT NOT NULL,
+    source_text TEXT NOT NULL,
+    context_hash TEXT NOT NULL,
+    translation TEXT NOT NULL,
+    hits INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_mt_cache_expires ON mt_cache(expires_at);
+
+-- +goose Down
+DROP INDEX idx_mt_cache_expires;
+DROP TABLE mt_cache;
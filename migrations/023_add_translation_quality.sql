@@ -0,0 +1,19 @@
+-- +goose Up
+-- AI quality estimation and back-translations per key and target file, for
+-- reviewers. target_hash is the hash of the assessed value, so assessments of
+-- values changed since are ignored.
+CREATE TABLE translation_quality (
+    file_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    target_hash TEXT NOT NULL,
+    score INTEGER,
+    issues TEXT NOT NULL DEFAULT '[]',
+    back_translation TEXT NOT NULL DEFAULT '',
+    translator TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (file_id, key),
+    FOREIGN KEY (file_id) REFERENCES files(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE translation_quality;
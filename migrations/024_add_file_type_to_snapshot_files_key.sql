@@ -0,0 +1,33 @@
+-- +goose Up
+-- A snapshot stores a base and a target file that can share a language code,
+-- so file_type is part of the key. SQLite cannot change a primary key in
+-- place, so the table is rebuilt.
+CREATE TABLE snapshot_files_new (
+    snapshot_id TEXT NOT NULL,
+    file_type TEXT NOT NULL,
+    language_code TEXT NOT NULL,
+    blob_hash TEXT NOT NULL,
+    PRIMARY KEY (snapshot_id, file_type, language_code),
+    FOREIGN KEY (snapshot_id) REFERENCES snapshots(id) ON DELETE CASCADE,
+    FOREIGN KEY (blob_hash) REFERENCES snapshot_blobs(hash)
+);
+INSERT INTO snapshot_files_new (snapshot_id, file_type, language_code, blob_hash)
+SELECT snapshot_id, file_type, language_code, blob_hash FROM snapshot_files;
+DROP TABLE snapshot_files;
+ALTER TABLE snapshot_files_new RENAME TO snapshot_files;
+
+-- +goose Down
+CREATE TABLE snapshot_files_old (
+    snapshot_id TEXT NOT NULL,
+    file_type TEXT NOT NULL,
+    language_code TEXT NOT NULL,
+    blob_hash TEXT NOT NULL,
+    PRIMARY KEY (snapshot_id, language_code),
+    FOREIGN KEY (snapshot_id) REFERENCES snapshots(id) ON DELETE CASCADE,
+    FOREIGN KEY (blob_hash) REFERENCES snapshot_blobs(hash)
+);
+-- Files of the same language in one snapshot cannot be kept; the base file is
+INSERT OR IGNORE INTO snapshot_files_old (snapshot_id, file_type, language_code, blob_hash)
+SELECT snapshot_id, file_type, language_code, blob_hash FROM snapshot_files ORDER BY file_type;
+DROP TABLE snapshot_files;
+ALTER TABLE snapshot_files_old RENAME TO snapshot_files;
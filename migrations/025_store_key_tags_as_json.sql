@@ -0,0 +1,14 @@
+-- +goose Up
+-- Key tags were stored comma-joined, which broke tags containing a comma.
+-- They are stored as a JSON array now; existing values are split on commas.
+UPDATE key_metadata SET tags = (
+    WITH RECURSIVE split(tag, rest) AS (
+        SELECT NULL, key_metadata.tags || ','
+        UNION ALL
+        SELECT substr(rest, 1, instr(rest, ',') - 1), substr(rest, instr(rest, ',') + 1) FROM split WHERE rest <> ''
+    )
+    SELECT json_group_array(tag) FROM split WHERE tag <> ''
+);
+
+-- +goose Down
+UPDATE key_metadata SET tags = (SELECT coalesce(group_concat(value, ','), '') FROM json_each(key_metadata.tags));
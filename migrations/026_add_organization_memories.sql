@@ -0,0 +1,24 @@
+-- +goose Up
+-- Organization translation memories belong to the creating session and can be
+-- linked to many projects. Their entries are stored in translation_memory
+-- with the owner 'memory:<id>'.
+CREATE TABLE memories (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    session_token TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Organization memories used by a project
+CREATE TABLE project_memories (
+    project_id TEXT NOT NULL,
+    memory_id TEXT NOT NULL,
+    PRIMARY KEY (project_id, memory_id),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (memory_id) REFERENCES memories(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DELETE FROM translation_memory WHERE owner LIKE 'memory:%';
+DROP TABLE project_memories;
+DROP TABLE memories;
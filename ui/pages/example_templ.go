@@ -99,7 +99,7 @@ func Example(
 				return templ_7745c5c3_Err
 			}
 			for _, key := range sortedKeys {
-				templ_7745c5c3_Err = translationField(key, baseFlat[key], targetFlat[key], "demo-project", "").Render(ctx, templ_7745c5c3_Buffer)
+				templ_7745c5c3_Err = translationField(key, baseFlat[key], targetFlat[key], "demo-project", "", FieldState{}).Render(ctx, templ_7745c5c3_Buffer)
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
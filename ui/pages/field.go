@@ -0,0 +1,84 @@
+package pages
+
+import (
+	"encoding/json"
+	"strings"
+
+	"templui/internal/models"
+	"templui/internal/qa"
+)
+
+// FieldState carries per-key annotations rendered alongside a translation field
+type FieldState struct {
+	Issues      []qa.Issue                // QA findings for the current value
+	Meta        models.KeyMetadata        // Description, context and limits for the key
+	Regions     []models.ScreenshotRegion // Screenshot hotspots linked to the key
+	IsOwner     bool                      // Owners can edit key metadata
+	Review      *models.ReviewFlag        // Set when a base file update changed the source text
+	Outdated    bool                      // The base value changed since the translation was made
+	Status      string                    // Workflow status, see models.Statuses
+	Role        string                    // Workflow role of the viewer
+	Suggestions int                       // Number of open suggestions
+	Questions   int                       // Number of unresolved comment threads
+	Terms       []qa.TermMatch            // Glossary terms found in the base value
+	Quality     *models.QualityAssessment // AI quality assessment of the current value
+	SwapOOB     bool                      // Rendered as an out-of-band swap of the row
+}
+
+// EditorFilters describes the active editor filters and the counts shown on them
+type EditorFilters struct {
+	View          string         // "full", "missing", "outdated", "questions" or "low_confidence"
+	Status        string         // Only show keys with this workflow status
+	OutdatedCount int            // Number of outdated translations
+	QuestionCount int            // Number of keys with unresolved comment threads
+	LowCount      int            // Number of translations with a low quality score
+	StatusCounts  map[string]int // Number of keys per workflow status
+}
+
+// qualityClass colours a quality score: low-confidence scores red, good ones green
+func qualityClass(score int) string {
+	switch {
+	case score < models.LowQualityScore:
+		return "bg-destructive/20 text-destructive"
+	case score < 90:
+		return "bg-yellow-500/20 text-yellow-600"
+	default:
+		return "bg-green-500/20 text-green-600"
+	}
+}
+
+// canTranslate reports whether a field offers the AI translate button: not to
+// suggesters, not for do-not-translate keys, and approved values only to
+// owners and reviewers
+func canTranslate(state FieldState) bool {
+	if state.Role == models.RoleSuggester || state.Meta.DoNotTranslate {
+		return false
+	}
+	return state.Status != models.StatusApproved || state.Role == models.RoleOwner || state.Role == models.RoleReviewer
+}
+
+// locked reports whether the viewer may not edit the value: approved values
+// are locked for translators
+func locked(state FieldState) bool {
+	return state.Status == models.StatusApproved && state.Role == models.RoleTranslator
+}
+
+// translateVals are the hx-vals of the AI translate button of a key
+func translateVals(key string, retranslate, approved bool) string {
+	vals := map[string]string{"keys": key}
+	if retranslate {
+		vals["retranslate"] = "true"
+	}
+	if approved {
+		vals["include_approved"] = "true"
+	}
+	b, _ := json.Marshal(vals)
+	return string(b)
+}
+
+// fieldSwapOOB is the hx-swap-oob of a field row. It selects the row by
+// attribute because keys may contain dots and other selector characters.
+func fieldSwapOOB(key string) string {
+	id := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace("field-" + key)
+	return `outerHTML:[id="` + id + `"]`
+}